@@ -23,186 +23,647 @@ import (
 //    OR (category = 'Furniture' AND price BETWEEN 100 AND 1000)
 //    OR (category = 'Books' AND stock > 0 AND author = 'John Doe');
 
-func (d *DbAdapter) prepareQueryStatement() {
+// statement is a built query, the SQL and
+// its arguments in placeholder order
+type statement struct {
+	query string
+	args  []interface{}
+	// Identifiers were used unquoted, see HasPotentialThreat
+	hasPotentialThreat bool
+}
+
+func (s *statement) concatenate(part string) {
+	if part == "" {
+		return
+	}
+	if s.query == "" {
+		s.query = part
+		return
+	}
+	s.query = fmt.Sprintf("%s %s", s.query, part)
+}
+
+func (s *statement) addArgs(args ...interface{}) {
+	s.args = append(s.args, args...)
+}
+
+// Add the arguments of a statement nested into s
+func (s *statement) addStatementArgs(nested statement) {
+	s.addArgs(nested.args...)
+	s.hasPotentialThreat = s.hasPotentialThreat || nested.hasPotentialThreat
+}
+
+// Build the statement from the builder state without
+// modifying it, so it can be called any number of times.
+// The parts are rendered in the order SQL expects them,
+// each one adding its own values to the arguments.
+func (d *DbAdapter) buildStatement() (statement, error) {
+	st := statement{}
+
+	if d.queryError != nil {
+		return st, d.queryError
+	}
+
+	var parts []func(st *statement) error
 	switch d.queryType {
 	case queryTypeSelect, queryTypeSelectRow:
-		d.prepareSelectStatement()
+		parts = []func(st *statement) error{
+			d.prepareSelectStatement,
+			d.initBuildJoin,
+			d.initBuildWhereClauses,
+			d.initBuildGroupBy,
+			d.initBuildHavingClauses,
+			d.initBuildCompounds,
+			d.initBuildOrderBy,
+			d.initBuildLimit,
+			d.initBuildLock,
+		}
 	case queryTypeUpdate:
-		d.prepareUpdateStatement()
+		// Joins are built by prepareUpdateStatement, see there
+		parts = []func(st *statement) error{
+			d.prepareUpdateStatement,
+			d.initBuildWhereClauses,
+			d.initBuildOrderBy,
+			d.initBuildLimit,
+		}
 	case queryTypeDelete:
-		d.prepareDeleteStatement()
+		// Joins are built by prepareDeleteStatement, see there
+		parts = []func(st *statement) error{
+			d.prepareDeleteStatement,
+			d.initBuildWhereClauses,
+			d.initBuildOrderBy,
+			d.initBuildLimit,
+		}
+	case queryTypeInsert:
+		// Nothing but the values follows an INSERT
+		parts = []func(st *statement) error{
+			d.prepareInsertStatement,
+		}
+	default:
+		return st, fmt.Errorf("No query to build. Call Select, Update, Delete or InsertValues first.")
 	}
+
+	for _, buildPart := range parts {
+		if err := buildPart(&st); err != nil {
+			return st, err
+		}
+	}
+
+	// Every part takes the values made before it was added,
+	// values left over would end up in the wrong place
+	if len(d.clauseValues) != 0 {
+		return st, fmt.Errorf("Values made with the Make* helpers are not used by any clause, make them right before the clause using them.")
+	}
+
+	return d.prependCommonTableExpressions(st)
+}
+
+// Build the statement with the placeholders of the dialect,
+// this is what gets executed. Statements nested into other
+// statements keep "?" until the outermost one is compiled.
+func (d *DbAdapter) compileStatement() (statement, error) {
+	st, err := d.buildStatement()
+	if err != nil {
+		return st, err
+	}
+	d.queryHasPotentialThreat = st.hasPotentialThreat
+	st.query = rebindPlaceholders(st.query, d.GetDialect())
+	return st, nil
 }
 
-func (d *DbAdapter) prepareColumnsForStatement() (string, error) {
+// Columns of a select may have an alias, the ones of an insert not
+func (d *DbAdapter) prepareColumnsForStatement(st *statement, withAlias bool) (string, error) {
 	if len(d.queryColumns) == 0 {
 		return "", fmt.Errorf("No columns available.")
 	}
-	return strings.Join(d.queryColumns, ", "), nil
+
+	if !withAlias {
+		columns, err := d.renderColumns(st, d.queryColumns)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(columns, ", "), nil
+	}
+
+	columns := []string{}
+	for i := 0; i < len(d.queryColumns); i++ {
+		column, err := d.renderAliasedIdentifier(st, d.prefixColumn(d.queryColumns[i]), false)
+		if err != nil {
+			return "", err
+		}
+		columns = append(columns, column)
+	}
+	return strings.Join(columns, ", "), nil
 }
 
-func (d *DbAdapter) prepareSelectStatement() {
+// The table of the builder with its alias, if set with As
+func (d *DbAdapter) prepareTableForStatement(st *statement) (string, error) {
+	table, err := d.renderAliasedIdentifier(st, d.dbTable, true)
+	if err != nil || d.tableAlias == "" {
+		return table, err
+	}
+	alias, err := d.renderAlias(st, d.tableAlias)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %s", table, As, alias), nil
+}
+
+func (d *DbAdapter) prepareSelectStatement(st *statement) error {
 	queryStringRaw := "SELECT %s FROM %s"
 	columnPlaceholder := "*"
 
 	if len(d.queryColumns) != 0 {
 
-		preparedColumns, err := d.prepareColumnsForStatement()
+		rawArgCount := len(st.args)
+		preparedColumns, err := d.prepareColumnsForStatement(st, true)
 		if err != nil {
-			d.handleQueryResultError(err)
-			return
+			return err
+		}
+		// Raw arguments are bound where their column is, Make* values
+		// only after the whole list
+		if len(d.queryColumnArgs) > 0 && len(st.args) > rawArgCount {
+			return fmt.Errorf("Columns made with the Make* helpers cannot be mixed with Raw expressions with arguments in one select list.")
 		}
 		columnPlaceholder = preparedColumns
+		st.addArgs(d.queryColumnArgs...)
+	}
+
+	// Scalar subqueries follow the columns
+	for i := 0; i < len(d.selectSubqueries); i++ {
+		subSt, err := buildSubquery(d.selectSubqueries[i].subquery)
+		if err != nil {
+			return err
+		}
+		alias, err := d.renderAlias(st, d.selectSubqueries[i].alias)
+		if err != nil {
+			return err
+		}
+		columnPlaceholder = fmt.Sprintf("%s, (%s) %s %s", columnPlaceholder, subSt.query, As, alias)
+		st.addStatementArgs(subSt)
+	}
+
+	if d.fromSubquery != nil {
+		subSt, err := buildSubquery(d.fromSubquery.subquery)
+		if err != nil {
+			return err
+		}
+		alias, err := d.renderAlias(st, d.fromSubquery.alias)
+		if err != nil {
+			return err
+		}
+		st.concatenate(fmt.Sprintf(queryStringRaw, columnPlaceholder, fmt.Sprintf("(%s) %s %s", subSt.query, As, alias)))
+		st.addStatementArgs(subSt)
+		return nil
 	}
 
-	d.concatenateQueryString(fmt.Sprintf(queryStringRaw, columnPlaceholder, d.dbTable))
+	table, err := d.prepareTableForStatement(st)
+	if err != nil {
+		return err
+	}
+
+	st.concatenate(fmt.Sprintf(queryStringRaw, columnPlaceholder, table))
+	return nil
+}
+
+func (d *DbAdapter) prepareInsertStatement(st *statement) error {
+	return d.prepareInsertRowsStatement(st, [][]interface{}{d.queryValues})
 }
 
-func (d *DbAdapter) prepareInsertStatement(valuePlaceHolders []string) {
-	queryStringRaw := "INSERT INTO %s (%s) VALUES(%s)"
+// INSERT with one VALUES list per row, see InsertBatch
+func (d *DbAdapter) prepareInsertRowsStatement(st *statement, rows [][]interface{}) error {
+	lenQueryColumns := len(d.queryColumns)
 
-	preparedColumns, err := d.prepareColumnsForStatement()
+	for i := 0; i < len(rows); i++ {
+		if lenQueryColumns != len(rows[i]) {
+			return fmt.Errorf("Insert could not be executed. Columns and values do not pair.")
+		}
+	}
+
+	queryStringRaw := "%s %s (%s) VALUES%s"
+
+	table, err := d.renderIdentifier(st, d.dbTable)
 	if err != nil {
-		d.handleQueryResultError(err)
-		return
+		return err
 	}
+
+	preparedColumns, err := d.prepareColumnsForStatement(st, false)
+	if err != nil {
+		return err
+	}
+
+	// INSERT IGNORE, REPLACE and ON DUPLICATE KEY UPDATE
+	verb, upsertClause, upsertArgs, err := d.prepareInsertModifiers(st, table)
+	if err != nil {
+		return err
+	}
+
+	rowPlaceHolders := []string{}
+	for i := 0; i < len(rows); i++ {
+		valuePlaceHolders := []string{}
+		for j := 0; j < lenQueryColumns; j++ {
+			value := preparationPlaceHolder
+			if isRawExpression(rows[i][j]) {
+				if value, err = d.resolveRawExpression(st, rows[i][j].(string)); err != nil {
+					return err
+				}
+			} else {
+				st.addArgs(rows[i][j])
+			}
+			valuePlaceHolders = append(valuePlaceHolders, value)
+		}
+		rowPlaceHolders = append(rowPlaceHolders, fmt.Sprintf("(%s)", strings.Join(valuePlaceHolders, ", ")))
+	}
+
 	columnPlaceholder := preparedColumns
-	d.concatenateQueryString(fmt.Sprintf(queryStringRaw, d.dbTable, columnPlaceholder, strings.Join(valuePlaceHolders, ", ")))
+	st.concatenate(fmt.Sprintf(queryStringRaw, verb, table, columnPlaceholder, strings.Join(rowPlaceHolders, ", ")))
+	st.concatenate(upsertClause)
+	st.addArgs(upsertArgs...)
+	return nil
 }
 
-func (d *DbAdapter) prepareUpdateStatement() {
+func (d *DbAdapter) prepareUpdateStatement(st *statement) error {
 	lenQueryColumns := len(d.queryColumns)
 	lenQueryValues := len(d.queryValues)
 
 	if lenQueryColumns == 0 || lenQueryValues == 0 || lenQueryColumns != lenQueryValues {
-		d.handleQueryResultError(fmt.Errorf("Update could not be executed. Columns and values do not pair."))
-		return
+		return fmt.Errorf("Update could not be executed. Columns and values do not pair.")
+	}
+
+	table, err := d.prepareTableForStatement(st)
+	if err != nil {
+		return err
 	}
 
 	queryStringRaw := "UPDATE %s"
-	// set the query string globally for Join clause
-	d.concatenateQueryString(fmt.Sprintf(queryStringRaw, d.dbTable))
+	st.concatenate(fmt.Sprintf(queryStringRaw, table))
 
 	// Since Joins in UPDATE Statement must be instanctiated
 	// prior to SET, we are exceptionally implementing JOINs
-	// in the prepare function and setting the d.Joins to nil
-	// since InitBuildJoins is called in makeQueryStatement
-	d.initBuildJoin()
-	// Now that the Join Clauses are take care of,
-	// unset Join Clauses
-	d.joins = nil
+	// in the prepare function rather than in buildStatement
+	if err := d.initBuildJoin(st); err != nil {
+		return err
+	}
+
+	columns, err := d.renderColumns(st, d.queryColumns)
+	if err != nil {
+		return err
+	}
 
 	columnValuePairPlaceholder := []string{}
 	for i := 0; i < lenQueryColumns; i++ {
-		columnValuePairPlaceholder = append(columnValuePairPlaceholder, fmt.Sprintf("%s %s %s", d.queryColumns[i], Equal, preparationPlaceHolder))
-		d.setAggregatedValueForPreparedStatement(d.queryValues[i])
+		value := preparationPlaceHolder
+		if isRawExpression(d.queryValues[i]) {
+			// Assigned an expression, e.g. stock - ?
+			if value, err = d.resolveRawExpression(st, d.queryValues[i].(string)); err != nil {
+				return err
+			}
+		} else {
+			st.addArgs(d.queryValues[i])
+		}
+		columnValuePairPlaceholder = append(columnValuePairPlaceholder, fmt.Sprintf("%s %s %s", columns[i], Equal, value))
+	}
+
+	st.concatenate(fmt.Sprintf("SET %s", strings.Join(columnValuePairPlaceholder, ", ")))
+	return nil
+}
+
+func (d *DbAdapter) prepareDeleteStatement(st *statement) error {
+	table, err := d.prepareTableForStatement(st)
+	if err != nil {
+		return err
+	}
+
+	if len(d.joins) == 0 && len(d.deleteTables) == 0 {
+		queryStringRaw := "DELETE FROM %s"
+		st.concatenate(fmt.Sprintf(queryStringRaw, table))
+		return nil
+	}
+
+	// The multi-table form, DELETE targets FROM table JOIN ...
+	if d.GetDialect().Name() != MySQL.Name() {
+		return fmt.Errorf("DELETE with JOIN is not supported by %s.", d.GetDialect().Name())
+	}
+	if len(d.orderBy) != 0 || d.queryLimit.Limit > 0 {
+		return fmt.Errorf("ORDER BY and LIMIT cannot be used in a DELETE with JOIN or multiple tables.")
+	}
+
+	targets, err := d.deleteTargets()
+	if err != nil {
+		return err
+	}
+	targets, err = d.renderIdentifiers(st, targets)
+	if err != nil {
+		return err
 	}
 
-	d.concatenateQueryString(fmt.Sprintf(" SET %s", strings.Join(columnValuePairPlaceholder, ", ")))
+	queryStringRaw := "DELETE %s FROM %s"
+	st.concatenate(fmt.Sprintf(queryStringRaw, strings.Join(targets, ", "), table))
+
+	// Joins belong between FROM and WHERE
+	return d.initBuildJoin(st)
 }
 
-func (d *DbAdapter) prepareDeleteStatement() {
-	queryStringRaw := "DELETE FROM %s"
-	d.concatenateQueryString(fmt.Sprintf(queryStringRaw, d.dbTable))
+func (d *DbAdapter) initBuildWhereClauses(st *statement) error {
+	return d.initBuildConditionGroups(st, "WHERE", d.whereClauses)
 }
 
-func (d *DbAdapter) initBuildWhereClauses() {
-	totalWhereGroups := len(d.whereClauses)
-	if totalWhereGroups == 0 {
-		return
+func (d *DbAdapter) initBuildHavingClauses(st *statement) error {
+	return d.initBuildConditionGroups(st, "HAVING", d.havingClauses)
+}
+
+// Render WHERE and HAVING groups, each group brings
+// the values collected when it was added
+func (d *DbAdapter) initBuildConditionGroups(st *statement, keyword string, groups []Where) error {
+	totalGroups := len(groups)
+	if totalGroups == 0 {
+		return nil
 	}
 	groupsStatement := ""
 
-	// fmt.Println(d.whereClauses)
-
-	for i := 0; i < totalWhereGroups; i++ {
-		conditionStatement := ""
-		totalSubClauses := len(d.whereClauses[i].Conditions)
+	for i := 0; i < totalGroups; i++ {
 		groupLogic := ""
-		// Skip the first logic
+		// Skip the first logic, unless it negates the group
 		if i != 0 {
-			groupLogic = fmt.Sprintf("%s ", d.whereClauses[i].WhereLogic)
+			groupLogic = fmt.Sprintf("%s ", makeConnectingLogic(groups[i].WhereLogic))
+		} else if groups[i].WhereLogic == NOT {
+			groupLogic = fmt.Sprintf("%s ", NOT)
 		}
 
-		conditions := d.whereClauses[i].Conditions
-		for j := 0; j < totalSubClauses; j++ {
-			condition := conditions[j]
+		conditionStatement, err := d.buildGroupConditions(st, groups[i])
+		if err != nil {
+			return err
+		}
 
-			conditionLogic := ""
+		groupsStatement += fmt.Sprintf("%s(%s) ", groupLogic, conditionStatement)
 
-			// Add the logic if it's not the first conditon
-			if j < (totalSubClauses - 1) {
-				conditionLogic = fmt.Sprintf(" %s ", condition.ClauseLogic)
+	}
+
+	st.concatenate(fmt.Sprintf("%s %s", keyword, strings.TrimRightFunc(groupsStatement, unicode.IsSpace)))
+	return nil
+}
+
+// Render the conditions of a group followed by the values
+// collected for it. Conditions bringing their own values can
+// not be combined with those, the order would not match.
+func (d *DbAdapter) buildGroupConditions(st *statement, group Where) (string, error) {
+	argsBefore := len(st.args)
+	conditionStatement, err := d.buildConditions(st, group.Conditions)
+	if err != nil {
+		return "", err
+	}
+	if len(group.args) != 0 && len(st.args) != argsBefore {
+		return "", fmt.Errorf("Conditions made with the Make* helpers cannot be mixed with conditions of the expression API in one group.")
+	}
+	st.addArgs(group.args...)
+	return conditionStatement, nil
+}
+
+// Render conditions joined by their logic, conditions made
+// with MakeNestedCondition are rendered recursively in
+// parentheses, NOT (...) if the nested group's logic is NOT
+func (d *DbAdapter) buildConditions(st *statement, conditions []Clause) (string, error) {
+	conditionStatement := ""
+	totalSubClauses := len(conditions)
+
+	for j := 0; j < totalSubClauses; j++ {
+		condition := conditions[j]
+
+		conditionLogic := ""
+
+		// Add the logic if it's not the last conditon
+		if j < (totalSubClauses - 1) {
+			conditionLogic = fmt.Sprintf(" %s ", makeConnectingLogic(condition.ClauseLogic))
+		}
+
+		if condition.Group != nil {
+			negation := ""
+			if condition.Group.WhereLogic == NOT {
+				negation = fmt.Sprintf("%s ", NOT)
 			}
+			nestedStatement, err := d.buildConditions(st, condition.Group.Conditions)
+			if err != nil {
+				return "", err
+			}
+			conditionStatement += fmt.Sprintf("%s(%s)%s", negation, nestedStatement, conditionLogic)
+			continue
+		}
 
-			conditionStatement += fmt.Sprintf("%s %s%s", condition.Column, condition.ValueAggregatedWithOperator, conditionLogic)
+		if condition.constant != "" {
+			conditionStatement += fmt.Sprintf("%s%s", condition.constant, conditionLogic)
+			continue
 		}
 
-		groupsStatement += fmt.Sprintf("%s(%s) ", groupLogic, conditionStatement)
-		// fmt.Println(conditionStatement)
+		column := condition.Column
+		valueAggregatedWithOperator := condition.ValueAggregatedWithOperator
+		if column != "" {
+			renderedColumn, err := d.renderColumn(st, column)
+			if err != nil {
+				return "", err
+			}
+			column = renderedColumn
+		}
+		if condition.fullTextColumns != nil {
+			fullTextColumns, err := d.renderColumns(st, condition.fullTextColumns)
+			if err != nil {
+				return "", err
+			}
+			column = d.GetDialect().MatchColumn(fullTextColumns)
+			valueAggregatedWithOperator = d.GetDialect().MatchSearchTerm(preparationPlaceHolder)
+		}
+		if condition.comparedColumn != "" {
+			comparedColumn, err := d.renderColumn(st, condition.comparedColumn)
+			if err != nil {
+				return "", err
+			}
+			valueAggregatedWithOperator = fmt.Sprintf("%s %s", valueAggregatedWithOperator, comparedColumn)
+		}
+		if condition.subquery != nil {
+			subSt, err := buildSubquery(condition.subquery)
+			if err != nil {
+				return "", err
+			}
+			valueAggregatedWithOperator = fmt.Sprintf("%s (%s)", valueAggregatedWithOperator, subSt.query)
+			st.addStatementArgs(subSt)
+		}
 
+		if column == "" {
+			// EXISTS (...)
+			conditionStatement += fmt.Sprintf("%s%s", valueAggregatedWithOperator, conditionLogic)
+		} else if valueAggregatedWithOperator == "" {
+			// RawCondition
+			conditionStatement += fmt.Sprintf("%s%s", column, conditionLogic)
+		} else {
+			conditionStatement += fmt.Sprintf("%s %s%s", column, valueAggregatedWithOperator, conditionLogic)
+		}
+		st.addArgs(condition.Args...)
 	}
-	// fmt.Println(groupsStatement)
 
-	d.concatenateQueryString(fmt.Sprintf("WHERE %s", strings.TrimRightFunc(groupsStatement, unicode.IsSpace)))
+	return conditionStatement, nil
 }
 
-func (d *DbAdapter) concatenateQueryString(statement string) {
-	d.queryString = fmt.Sprintf("%s %s", d.queryString, statement)
+// NOT does not connect two conditions on its own
+func makeConnectingLogic(logic ClauseLogic) string {
+	if logic == NOT {
+		return fmt.Sprintf("%s %s", AND, NOT)
+	}
+	return string(logic)
 }
 
-func (d *DbAdapter) initBuildOrderBy() {
+func (d *DbAdapter) initBuildOrderBy(st *statement) error {
 	lengthOrderBy := len(d.orderBy)
 
 	if lengthOrderBy == 0 {
-		return
+		return nil
 	}
 
 	orderBySequences := []string{}
 
 	for i := 0; i < lengthOrderBy; i++ {
 		orderBy := d.orderBy[i]
-		orderBySequences = append(orderBySequences, fmt.Sprintf("%s %s", orderBy.Column, orderBy.Order))
+		column, err := d.renderColumn(st, orderBy.Column)
+		if err != nil {
+			return err
+		}
+		// The direction often comes from a request as well
+		order := Order(strings.ToUpper(strings.TrimSpace(string(orderBy.Order))))
+		if order != Asc && order != Desc && order != "" {
+			return fmt.Errorf("Invalid order %q for %s, use Asc or Desc.", orderBy.Order, orderBy.Column)
+		}
+		orderBySequences = append(orderBySequences, strings.TrimSpace(fmt.Sprintf("%s %s", column, order)))
+		st.addArgs(orderBy.args...)
 	}
 
-	d.concatenateQueryString(fmt.Sprintf("ORDER BY %s", strings.Join(orderBySequences, ", ")))
+	st.concatenate(fmt.Sprintf("ORDER BY %s", strings.Join(orderBySequences, ", ")))
+	return nil
 }
 
-func (d *DbAdapter) initBuildGroupBy() {
+func (d *DbAdapter) initBuildGroupBy(st *statement) error {
 	if len(d.groupBy) == 0 {
-		return
+		return nil
+	}
+
+	rawArgCount := len(st.args)
+	groupBy, err := d.renderColumns(st, d.groupBy)
+	if err != nil {
+		return err
+	}
+	if len(d.groupByArgs) > 0 && len(st.args) > rawArgCount {
+		return fmt.Errorf("Columns made with the Make* helpers cannot be mixed with Raw expressions with arguments in one GROUP BY.")
 	}
 
-	d.concatenateQueryString(fmt.Sprintf("GROUP BY %s", strings.Join(d.groupBy, ", ")))
+	st.concatenate(fmt.Sprintf("GROUP BY %s", strings.Join(groupBy, ", ")))
+	st.addArgs(d.groupByArgs...)
+	return nil
 }
 
-func (d *DbAdapter) initBuildLimit() {
-	limit := ""
-	offset := ""
-	if d.queryLimit.Limit > 0 {
-		limit = fmt.Sprintf("LIMIT %d", d.queryLimit.Limit)
+func (d *DbAdapter) initBuildLimit(st *statement) error {
+	if d.queryLimit.Limit <= 0 {
+		return nil
+	}
 
-		if d.queryType == queryTypeSelect || d.queryType == queryTypeSelectRow {
-			offset = fmt.Sprintf(" OFFSET %d", d.queryLimit.Offset)
-		}
+	if d.queryType == queryTypeSelect || d.queryType == queryTypeSelectRow {
+		st.concatenate(d.GetDialect().Limit(d.queryLimit.Limit, d.queryLimit.Offset))
+		return nil
 	}
 
-	d.concatenateQueryString(fmt.Sprintf("%s%s", limit, offset))
+	// UPDATE and DELETE take no offset
+	limit, err := d.GetDialect().WriteLimit(d.queryLimit.Limit)
+	if err != nil {
+		return err
+	}
+	st.concatenate(limit)
+	return nil
 }
 
-func (d *DbAdapter) initBuildJoin() {
+func (d *DbAdapter) initBuildJoin(st *statement) error {
 	lengthJoins := len(d.joins)
 
 	if lengthJoins == 0 {
-		return
+		return nil
 	}
 
 	joinSequences := []string{}
 
 	for i := 0; i < lengthJoins; i++ {
-		join := d.joins[i]
-		joinSequences = append(joinSequences, fmt.Sprintf("%s %s ON %s %s %s", join.JoinType, join.ForignTable, join.PrimaryKey, Equal, join.ForignKey))
+		joinSequence, err := d.buildJoin(st, d.joins[i])
+		if err != nil {
+			return err
+		}
+		joinSequences = append(joinSequences, joinSequence)
+	}
+
+	st.concatenate(strings.Join(joinSequences, " "))
+	return nil
+}
+
+// A join renders one of ON, USING or nothing for CROSS JOIN,
+// the values of the joined subquery come before the ones of ON
+func (d *DbAdapter) buildJoin(st *statement, join join) (string, error) {
+	if join.JoinType == StraightJoin && d.GetDialect().Name() != MySQL.Name() {
+		return "", fmt.Errorf("%s is not supported by %s.", StraightJoin, d.GetDialect().Name())
+	}
+	if join.JoinType == CrossJoin && (join.on != nil || len(join.using) != 0 || join.PrimaryKey != "") {
+		return "", fmt.Errorf("%s takes no ON or USING condition.", CrossJoin)
 	}
 
-	d.concatenateQueryString(fmt.Sprintf("%s", strings.Join(joinSequences, " ")))
+	var table string
+	if join.subquery != nil {
+		subSt, err := buildSubquery(join.subquery)
+		if err != nil {
+			return "", err
+		}
+		table = fmt.Sprintf("(%s)", subSt.query)
+		st.addStatementArgs(subSt)
+	} else {
+		renderedTable, err := d.renderAliasedIdentifier(st, join.ForignTable, true)
+		if err != nil {
+			return "", err
+		}
+		table = renderedTable
+	}
+	if join.Alias != "" {
+		alias, err := d.renderAlias(st, join.Alias)
+		if err != nil {
+			return "", err
+		}
+		table = fmt.Sprintf("%s %s %s", table, As, alias)
+	}
+
+	switch {
+	case join.on != nil:
+		conditionStatement, err := d.buildGroupConditions(st, *join.on)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s ON %s", join.JoinType, table, conditionStatement), nil
+	case len(join.using) != 0:
+		using, err := d.renderIdentifiers(st, join.using)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s USING (%s)", join.JoinType, table, strings.Join(using, ", ")), nil
+	case join.PrimaryKey != "":
+		keys, err := d.renderIdentifiers(st, []string{join.PrimaryKey, join.ForignKey})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s ON %s %s %s", join.JoinType, table, keys[0], Equal, keys[1]), nil
+	case join.JoinType == CrossJoin:
+		return fmt.Sprintf("%s %s", join.JoinType, table), nil
+	}
+	return "", fmt.Errorf("%s %s needs an ON or USING condition.", join.JoinType, table)
+}
+
+func (d *DbAdapter) initBuildLock(st *statement) error {
+	if d.lockMode == "" {
+		return nil
+	}
+
+	lock, err := d.GetDialect().Lock(d.lockMode)
+	if err != nil {
+		return err
+	}
+	st.concatenate(lock)
+	return nil
 }
@@ -0,0 +1,432 @@
+package querybuilder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// A case builds a statement on a fresh builder for the table
+// users and compares the result of ToSQL
+type toSQLCase struct {
+	name  string
+	build func(d *DbAdapter) *DbAdapter
+	query string
+	args  []interface{}
+	// Part of the expected error, the query is not checked then
+	err string
+}
+
+func newTestAdapter(dialect Dialect) *DbAdapter {
+	d := &DbAdapter{}
+	d.SetDialect(dialect)
+	d.SetTableAndPrefix(TableDetails{Table: "users"})
+	return d
+}
+
+func runToSQLCases(t *testing.T, newAdapter func() *DbAdapter, cases []toSQLCase) {
+	t.Helper()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := newAdapter()
+			query, args, err := c.build(d.NewQuery()).ToSQL()
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q, got %v (query %q)", c.err, err, query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if query != c.query {
+				t.Errorf("query\n got: %s\nwant: %s", query, c.query)
+			}
+			if len(args) != 0 || len(c.args) != 0 {
+				if !reflect.DeepEqual(args, c.args) {
+					t.Errorf("args\n got: %v\nwant: %v", args, c.args)
+				}
+			}
+		})
+	}
+}
+
+func TestToSQLCanonicalOrder(t *testing.T) {
+	runToSQLCases(t, func() *DbAdapter { return newTestAdapter(MySQL) }, []toSQLCase{
+		{
+			name: "select parts in any call order",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.ForUpdate().
+					Limit(10, 20).
+					OrderBy(OrderBy{Column: "city", Order: Desc}).
+					HavingCondition(Col("total").Gt(5)).
+					GroupBy([]string{"city"}).
+					WhereCondition(Col("active").Eq(true)).
+					Join(InnerJoin, "orders", "orders.user_id", "users.id").
+					SelectByColumns([]string{"city", "COUNT(*) AS total"})
+			},
+			query: "SELECT `city`, COUNT(*) AS total FROM `users` INNER JOIN `orders` ON `orders`.`user_id` = `users`.`id` WHERE (`active` = ?) GROUP BY `city` HAVING (`total` > ?) ORDER BY `city` DESC LIMIT 10 OFFSET 20 FOR UPDATE",
+			args:  []interface{}{true, 5},
+		},
+		{
+			name: "update joins before SET",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Limit(5).
+					WhereCondition(Col("users.id").Gt(1)).
+					JoinOnCondition(InnerJoin, "orders", "o", Col("o.user_id").EqCol("users.id").And(Col("o.status").Eq("paid"))).
+					Update([]string{"users.paid"}, []interface{}{true})
+			},
+			query: "UPDATE `users` INNER JOIN `orders` AS `o` ON `o`.`user_id` = `users`.`id` AND `o`.`status` = ? SET `users`.`paid` = ? WHERE (`users`.`id` > ?) LIMIT 5",
+			args:  []interface{}{"paid", true, 1},
+		},
+		{
+			name: "delete",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Limit(100).OrderBy(OrderBy{Column: "id"}).Delete().WhereCondition(Col("id").Lt(7))
+			},
+			query: "DELETE FROM `users` WHERE (`id` < ?) ORDER BY `id` LIMIT 100",
+			args:  []interface{}{7},
+		},
+		{
+			name: "insert with upsert",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.OnDuplicateKeyUpdate([]string{"id"}, UpsertColumn{Column: "hits", Action: Increment}).
+					InsertValues([]string{"id", "hits"}, []interface{}{1, 1})
+			},
+			query: "INSERT INTO `users` (`id`, `hits`) VALUES(?, ?) ON DUPLICATE KEY UPDATE `hits` = `hits` + ?",
+			args:  []interface{}{1, 1, 1},
+		},
+		{
+			name:  "nothing to build",
+			build: func(d *DbAdapter) *DbAdapter { return d },
+			err:   "No query to build",
+		},
+	})
+}
+
+func TestToSQLArgumentOrder(t *testing.T) {
+	runToSQLCases(t, func() *DbAdapter { return newTestAdapter(MySQL) }, []toSQLCase{
+		{
+			name: "where, having and join on",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.HavingCondition(Col("n").Gt(3)).
+					WhereCondition(Col("a").Eq(1).Or(Col("b").In(2, 3))).
+					JoinOnCondition(LeftJoin, "orders", "o", Col("o.user_id").EqCol("users.id").And(Col("o.total").Gte(4))).
+					GroupBy([]string{"a"}).
+					SelectByColumns([]string{"a"})
+			},
+			query: "SELECT `a` FROM `users` LEFT JOIN `orders` AS `o` ON `o`.`user_id` = `users`.`id` AND `o`.`total` >= ? WHERE (`a` = ? OR `b` IN (?, ?)) GROUP BY `a` HAVING (`n` > ?)",
+			args:  []interface{}{4, 1, 2, 3, 3},
+		},
+		{
+			name: "Make* values of where and having",
+			build: func(d *DbAdapter) *DbAdapter {
+				d.Having(d.MakeWhereGroup(AND, []Clause{d.MakeCondition(AND, "n", d.MakeAggregatedValueWithOperator(GreaterThan, 2))}))
+				d.Where(d.MakeWhereGroup(AND, []Clause{
+					d.MakeCondition(AND, "a", d.MakeBetween(1, 9)),
+					d.MakeCondition(AND, "b", d.MakeIn([]interface{}{"x", "y"})),
+				}))
+				return d.GroupBy([]string{"a"}).Select()
+			},
+			query: "SELECT * FROM `users` WHERE (`a` BETWEEN ? AND ? AND `b` IN (?, ?)) GROUP BY `a` HAVING (`n` > ?)",
+			args:  []interface{}{1, 9, "x", "y", 2},
+		},
+		{
+			name: "Make* values of the select list and order by",
+			build: func(d *DbAdapter) *DbAdapter {
+				d.OrderBy(OrderBy{Column: "MATCH(title) " + d.MakeMatchAgainstSearchTerm("order"), Order: Desc})
+				d.Where(d.MakeWhereGroup(AND, []Clause{d.MakeCondition(AND, "a", d.MakeAggregatedValueWithOperator(Equal, 1))}))
+				return d.SelectByColumns([]string{"MATCH(title) " + d.MakeMatchAgainstSearchTerm("select")})
+			},
+			query: "SELECT MATCH(title) AGAINST(?) FROM `users` WHERE (`a` = ?) ORDER BY MATCH(title) AGAINST(?) DESC",
+			args:  []interface{}{"select", 1, "order"},
+		},
+		{
+			name: "expression conditions leave Make* values alone",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.SelectByColumns([]string{"MATCH(title) " + d.MakeMatchAgainstSearchTerm("go")}).
+					WhereCondition(Col("status").Eq("published"))
+			},
+			query: "SELECT MATCH(title) AGAINST(?) FROM `users` WHERE (`status` = ?)",
+			args:  []interface{}{"go", "published"},
+		},
+		{
+			name: "mixed condition kinds in one group",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Select().Where(d.MakeWhereGroup(AND, []Clause{
+					d.MakeCondition(AND, "a", d.MakeAggregatedValueWithOperator(Equal, 1)),
+					Col("b").Eq(2),
+				}))
+			},
+			err: "cannot be mixed",
+		},
+		{
+			name: "values not used by any clause",
+			build: func(d *DbAdapter) *DbAdapter {
+				d.MakeAggregatedValueWithOperator(Equal, 1)
+				return d.Select()
+			},
+			err: "not used by any clause",
+		},
+		{
+			name: "subqueries in the select list, from and where",
+			build: func(d *DbAdapter) *DbAdapter {
+				count := d.Table("orders").SelectByColumns([]string{"COUNT(*)"}).WhereCondition(Col("total").Gt(1))
+				derived := d.Table("users").Select().WhereCondition(Col("active").Eq(2))
+				paid := d.Table("orders").SelectByColumns([]string{"user_id"}).WhereCondition(Col("status").Eq(3))
+				return d.WhereCondition(Col("id").InSubquery(paid).And(Col("id").Gt(4))).
+					FromSubquery(derived, "u").
+					SelectByColumns([]string{"id"}).
+					SelectSubquery(count, "orders")
+			},
+			query: "SELECT `id`, (SELECT COUNT(*) FROM `orders` WHERE (`total` > ?)) AS `orders` FROM (SELECT * FROM `users` WHERE (`active` = ?)) AS `u` WHERE (`id` IN (SELECT `user_id` FROM `orders` WHERE (`status` = ?)) AND `id` > ?)",
+			args:  []interface{}{1, 2, 3, 4},
+		},
+		{
+			name: "ctes and compounds",
+			build: func(d *DbAdapter) *DbAdapter {
+				recent := d.Table("orders").SelectByColumns([]string{"user_id"}).WhereCondition(Col("year").Eq(1))
+				leads := d.Table("leads").SelectByColumns([]string{"email"}).WhereCondition(Col("score").Gt(3))
+				return d.With("recent", recent).
+					SelectByColumns([]string{"email"}).
+					WhereCondition(Col("id").InSubquery(d.Table("recent").SelectByColumns([]string{"user_id"})).And(Col("age").Gt(2))).
+					Union(leads).
+					OrderBy(OrderBy{Column: "email"}).
+					Limit(5)
+			},
+			query: "WITH `recent` AS (SELECT `user_id` FROM `orders` WHERE (`year` = ?)) SELECT `email` FROM `users` WHERE (`id` IN (SELECT `user_id` FROM `recent`) AND `age` > ?) UNION SELECT `email` FROM `leads` WHERE (`score` > ?) ORDER BY `email` LIMIT 5 OFFSET 0",
+			args:  []interface{}{1, 2, 3},
+		},
+		{
+			name: "Make* and Raw columns in one select list",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.SelectByColumns([]string{"MATCH(title) " + d.MakeMatchAgainstSearchTerm("x"), d.Raw("price * ?", 2)})
+			},
+			err: "cannot be mixed with Raw expressions",
+		},
+		{
+			name: "Make* and Raw columns in one GROUP BY",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Select().GroupBy([]string{"MATCH(title) " + d.MakeMatchAgainstSearchTerm("x"), d.Raw("price DIV ?", 10)})
+			},
+			err: "cannot be mixed with Raw expressions",
+		},
+		{
+			name: "Make* and Raw columns without arguments",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.SelectByColumns([]string{"MATCH(title) " + d.MakeMatchAgainstSearchTerm("x"), d.Raw("NOW()")})
+			},
+			query: "SELECT MATCH(title) AGAINST(?), NOW() FROM `users`",
+			args:  []interface{}{"x"},
+		},
+		{
+			name: "raw expressions bind in place",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.OrderBy(OrderBy{Column: d.Raw("FIELD(status, ?, ?)", "a", "b")}).
+					WhereCondition(RawCondition(d.Raw("score > ?", 4))).
+					SelectByColumns([]string{d.MakeAsField(d.Raw("price * ?", 2), "gross")})
+			},
+			query: "SELECT price * ? AS `gross` FROM `users` WHERE (score > ?) ORDER BY FIELD(status, ?, ?)",
+			args:  []interface{}{2, 4, "a", "b"},
+		},
+	})
+}
+
+func TestToSQLIdentifiers(t *testing.T) {
+	runToSQLCases(t, func() *DbAdapter { return newTestAdapter(MySQL) }, []toSQLCase{
+		{
+			name: "plain, qualified and star",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.SelectByColumns([]string{"id", "u.*", "users.name AS author", "`odd``name`"})
+			},
+			query: "SELECT `id`, `u`.*, `users`.`name` AS `author`, `odd``name` FROM `users`",
+		},
+		{
+			name: "expressions are kept",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.SelectByColumns([]string{"COUNT(*)"}).GroupBy([]string{"YEAR(created)"})
+			},
+			query: "SELECT COUNT(*) FROM `users` GROUP BY YEAR(created)",
+		},
+		{
+			name: "only AS makes a column alias",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.SelectByColumns([]string{d.MakeDistinct("city"), "name as n"})
+			},
+			query: "SELECT DISTINCT city, `name` AS `n` FROM `users`",
+		},
+		{
+			name: "tables take implicit aliases",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Table("users u").Select().Join(InnerJoin, "orders o", "o.user_id", "u.id")
+			},
+			query: "SELECT * FROM `users` AS `u` INNER JOIN `orders` AS `o` ON `o`.`user_id` = `u`.`id`",
+		},
+		{
+			name: "order direction is validated",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Select().OrderBy(OrderBy{Column: "id", Order: "ASC; DROP TABLE users"})
+			},
+			err: "Invalid order",
+		},
+		{
+			name: "raw of another builder",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Select().OrderBy(OrderBy{Column: d.Table("users").Raw("id")})
+			},
+			err: "another query builder",
+		},
+	})
+
+	strict := func() *DbAdapter {
+		d := newTestAdapter(MySQL)
+		d.SetStrictIdentifiers(true)
+		return d
+	}
+	runToSQLCases(t, strict, []toSQLCase{
+		{
+			name: "strict rejects expressions",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Select().OrderBy(OrderBy{Column: "id; DROP TABLE users"})
+			},
+			err: "is not a plain identifier",
+		},
+		{
+			name: "strict accepts empty IN and NOT IN",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Select().WhereCondition(Col("id").In().And(Col("id").NotIn()).Or(Col("a").Eq(1)))
+			},
+			query: "SELECT * FROM `users` WHERE ((1 = 0 AND 1 = 1) OR `a` = ?)",
+			args:  []interface{}{1},
+		},
+		{
+			name: "strict accepts raw",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.SelectByColumns([]string{d.MakeAsField(d.Raw("COUNT(*)"), "total")})
+			},
+			query: "SELECT COUNT(*) AS `total` FROM `users`",
+		},
+	})
+}
+
+func TestToSQLAutoFieldPrefix(t *testing.T) {
+	prefixed := func() *DbAdapter {
+		d := &DbAdapter{}
+		d.SetDialect(MySQL)
+		d.SetTableAndPrefix(TableDetails{Table: "users", Prefix: "usr_"})
+		d.SetAutoFieldPrefix(true)
+		return d
+	}
+	runToSQLCases(t, prefixed, []toSQLCase{
+		{
+			name: "distinct is not prefixed",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.SelectByColumns([]string{d.MakeDistinct("usr_city"), "name AS n"}).OrderBy(OrderBy{Column: "n"})
+			},
+			query: "SELECT DISTINCT usr_city, `usr_name` AS `n` FROM `users` ORDER BY `n`",
+		},
+	})
+}
+
+func TestToSQLHasPotentialThreat(t *testing.T) {
+	d := newTestAdapter(MySQL)
+	q := d.NewQuery().Select().OrderBy(OrderBy{Column: "id; DROP TABLE users"})
+	if _, _, err := q.ToSQL(); err != nil || !q.HasPotentialThreat() {
+		t.Errorf("expected a potential threat, got %v, %v", q.HasPotentialThreat(), err)
+	}
+
+	q = d.NewQuery().SelectByColumns([]string{d.MakeDistinct("city")})
+	if _, _, err := q.ToSQL(); err != nil || !q.HasPotentialThreat() {
+		t.Errorf("expected a potential threat for DISTINCT, got %v, %v", q.HasPotentialThreat(), err)
+	}
+
+	q = d.NewQuery().Select().WhereCondition(Col("id").In())
+	if _, _, err := q.ToSQL(); err != nil || q.HasPotentialThreat() {
+		t.Errorf("expected no potential threat for an empty IN, got %v, %v", q.HasPotentialThreat(), err)
+	}
+
+	q = d.NewQuery().Select().OrderBy(OrderBy{Column: "id"})
+	if _, _, err := q.ToSQL(); err != nil || q.HasPotentialThreat() {
+		t.Errorf("expected no potential threat, got %v, %v", q.HasPotentialThreat(), err)
+	}
+}
+
+func TestToSQLPostgreSQL(t *testing.T) {
+	runToSQLCases(t, func() *DbAdapter { return newTestAdapter(PostgreSQL) }, []toSQLCase{
+		{
+			name: "placeholders are numbered",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Select().
+					WhereCondition(Col("a").Eq(1).And(Col("b").In(2, 3))).
+					HavingCondition(Col("c").Gt(4)).
+					GroupBy([]string{"a"})
+			},
+			query: `SELECT * FROM "users" WHERE ("a" = $1 AND "b" IN ($2, $3)) GROUP BY "a" HAVING ("c" > $4)`,
+			args:  []interface{}{1, 2, 3, 4},
+		},
+		{
+			name: "quoted question marks are kept",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.SelectByColumns([]string{"'?' AS q"}).WhereCondition(Col("a").Eq(1))
+			},
+			query: `SELECT '?' AS q FROM "users" WHERE ("a" = $1)`,
+			args:  []interface{}{1},
+		},
+		{
+			name: "subquery placeholders continue the numbering",
+			build: func(d *DbAdapter) *DbAdapter {
+				paid := d.Table("orders").SelectByColumns([]string{"user_id"}).WhereCondition(Col("status").Eq("paid"))
+				return d.Select().WhereCondition(Col("a").Eq(1).And(Col("id").InSubquery(paid)))
+			},
+			query: `SELECT * FROM "users" WHERE ("a" = $1 AND "id" IN (SELECT "user_id" FROM "orders" WHERE ("status" = $2)))`,
+			args:  []interface{}{1, "paid"},
+		},
+		{
+			name: "upsert",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.OnDuplicateKeyUpdate([]string{"id"}, UpsertColumn{Column: "name", Action: UseNewValue}, UpsertColumn{Column: "hits", Action: Increment}).
+					InsertValues([]string{"id", "name"}, []interface{}{1, "a"})
+			},
+			query: `INSERT INTO "users" ("id", "name") VALUES($1, $2) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "hits" = "users"."hits" + $3`,
+			args:  []interface{}{1, "a", 1},
+		},
+		{
+			name: "no limit in delete",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Delete().Limit(1)
+			},
+			err: "LIMIT is not supported",
+		},
+	})
+}
+
+func TestChunkInsertRows(t *testing.T) {
+	rows := [][]interface{}{}
+	for i := 0; i < 10; i++ {
+		rows = append(rows, []interface{}{i, "a"})
+	}
+	two := func(row []interface{}) int { return len(row) }
+
+	cases := []struct {
+		name            string
+		maxPlaceholders int
+		maxPacketBytes  int
+		sizes           []int
+	}{
+		{"placeholders", 8, defaultMaxPacketBytes, []int{4, 4, 2}},
+		// 9 placeholders of which one is taken by an upsert value
+		{"placeholders left by an upsert", 9 - 1, defaultMaxPacketBytes, []int{4, 4, 2}},
+		{"at least one row", 1, defaultMaxPacketBytes, []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}},
+		{"packet size", 100, 2 * estimateRowBytes(rows[0]), []int{2, 2, 2, 2, 2}},
+	}
+	for _, c := range cases {
+		chunks := chunkInsertRows(rows, two, c.maxPlaceholders, c.maxPacketBytes)
+		sizes := []int{}
+		for _, chunk := range chunks {
+			sizes = append(sizes, len(chunk))
+		}
+		if !reflect.DeepEqual(sizes, c.sizes) {
+			t.Errorf("%s: got chunks of %v, want %v", c.name, sizes, c.sizes)
+		}
+	}
+}
@@ -19,43 +19,97 @@ func (d *DbAdapter) Limit(limit int, offset ...int) *DbAdapter {
 	return d
 }
 
+// Lock the selected rows until the end of the transaction
+func (d *DbAdapter) ForUpdate() *DbAdapter {
+	d.lockMode = LockForUpdate
+	return d
+}
+
+// Lock the selected rows against writes of other
+// transactions until the end of the transaction
+func (d *DbAdapter) ForShare() *DbAdapter {
+	d.lockMode = LockForShare
+	return d
+}
+
+// Like Where, GroupBy and OrderBy take the values collected
+// by the Make* helpers so far, e.g. for MATCH(...) AGAINST(?).
+// GroupBy cannot combine them with Raw columns taking arguments.
 func (d *DbAdapter) GroupBy(groupBy []string) *DbAdapter {
 	d.groupBy = groupBy
+	d.groupByArgs = d.takeAggregatedValuesForClauses()
 	return d
 }
 
 func (d *DbAdapter) OrderBy(orderBy OrderBy) *DbAdapter {
+	orderBy.args = d.takeAggregatedValuesForClauses()
 	d.orderBy = append(d.orderBy, orderBy)
 	return d
 }
 
+// The values collected by the Make* helpers so far
+// belong to this group, make the conditions of a group
+// right before adding it
 func (d *DbAdapter) Where(whereGroup Where) *DbAdapter {
+	whereGroup.args = d.takeAggregatedValuesForClauses()
 	d.whereClauses = append(d.whereClauses, whereGroup)
 	return d
 }
 
+// Filter the groups of GroupBy, the conditions are made
+// the same way as for Where, usually on aggregates
+// Usage: d.GroupBy([]string{"city"}).Having(d.MakeWhereGroup(AND, conditions))
+func (d *DbAdapter) Having(havingGroup Where) *DbAdapter {
+	havingGroup.args = d.takeAggregatedValuesForClauses()
+	d.havingClauses = append(d.havingClauses, havingGroup)
+	return d
+}
+
 func (d *DbAdapter) MakeCondition(conditionLogic ClauseLogic, column string, valueAggregatedWithOperator string) Clause {
 	return Clause{ClauseLogic: conditionLogic, Column: column, ValueAggregatedWithOperator: valueAggregatedWithOperator}
 }
 
+// A condition made of a whole group, nested to any depth.
+// The logic of the group is only used to negate it with NOT.
+// Usage: a = 1 AND (b = 2 OR c = 3)
+//
+//	d.MakeWhereGroup(AND, []Clause{
+//		d.MakeCondition(AND, "a", d.MakeAggregatedValueWithOperator(Equal, 1)),
+//		d.MakeNestedCondition(AND, d.MakeWhereGroup(AND, []Clause{
+//			d.MakeCondition(OR, "b", d.MakeAggregatedValueWithOperator(Equal, 2)),
+//			d.MakeCondition(AND, "c", d.MakeAggregatedValueWithOperator(Equal, 3)),
+//		})),
+//	})
+func (d *DbAdapter) MakeNestedCondition(conditionLogic ClauseLogic, group Where) Clause {
+	return Clause{ClauseLogic: conditionLogic, Group: &group}
+}
+
 func (d *DbAdapter) MakeWhereGroup(wherLogic ClauseLogic, conditions []Clause) Where {
 	return Where{WhereLogic: wherLogic, Conditions: conditions}
 }
 
 // Usage: Max(column), Count(*), AVG(column)
+// The function is translated by the dialect, e.g.
+// Year is EXTRACT(YEAR FROM column) on PostgreSQL
 func (d *DbAdapter) MakeMySQLFunction(column string, mysqlFunction MySqlFunction) string {
-	return fmt.Sprintf(string(mysqlFunction), column)
+	function := d.GetDialect().Function(mysqlFunction)
+	if !strings.Contains(function, "%s") {
+		return function
+	}
+	return fmt.Sprintf(function, column)
 }
 
 // Fulltext Search Usage: WHERE MATCH(column1, column2) AGAINST('search term');
+// PostgreSQL: to_tsvector(...) @@ plainto_tsquery('search term'),
+// SQLite: fts_table MATCH 'search term'
 func (d *DbAdapter) MakeMatchAgainstColumn(columns []string) string {
-	return fmt.Sprintf(Match, strings.Join(columns, ", "))
+	return d.GetDialect().MatchColumn(columns)
 }
 
 // Fulltext Search Usage: WHERE MATCH(column1, column2) AGAINST('search term');
 func (d *DbAdapter) MakeMatchAgainstSearchTerm(searchTerm string) string {
 	d.setAggregatedValueForClauses(searchTerm)
-	return fmt.Sprintf(Against, preparationPlaceHolder)
+	return d.GetDialect().MatchSearchTerm(preparationPlaceHolder)
 }
 
 func (d *DbAdapter) MakeAsField(column, asParam string) string {
@@ -65,7 +119,7 @@ func (d *DbAdapter) MakeAsField(column, asParam string) string {
 func (d *DbAdapter) MakeBetween(rangeBegin, rangeEnd interface{}) string {
 	d.setAggregatedValueForClauses(rangeBegin)
 	d.setAggregatedValueForClauses(rangeEnd)
-	return fmt.Sprintf("%s (%s %s %s)", Between, preparationPlaceHolder, AND, preparationPlaceHolder)
+	return fmt.Sprintf("%s %s %s %s", Between, preparationPlaceHolder, AND, preparationPlaceHolder)
 }
 
 func (d *DbAdapter) MakeDistinct(column string) string {
@@ -95,16 +149,11 @@ func (d *DbAdapter) makeInAndNotIn(operator string, items []interface{}) string
 }
 
 func (d *DbAdapter) setAggregatedValueForClauses(value interface{}) {
-	// d.queryAggregatedValuesPreparedStatement = append(d.queryAggregatedValuesPreparedStatement, value)
 	d.clauseValues = append(d.clauseValues, value)
 }
 
-func (d *DbAdapter) setAggregatedValueForPreparedStatement(value interface{}) {
-	d.queryAggregatedValuesPreparedStatement = append(d.queryAggregatedValuesPreparedStatement, value)
-}
-
-func (d *DbAdapter) prepareClauseValuesForPreparedStatement() {
-	for i := 0; i < len(d.clauseValues); i++ {
-		d.queryAggregatedValuesPreparedStatement = append(d.queryAggregatedValuesPreparedStatement, d.clauseValues[i])
-	}
+func (d *DbAdapter) takeAggregatedValuesForClauses() []interface{} {
+	values := d.clauseValues
+	d.clauseValues = nil
+	return values
 }
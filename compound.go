@@ -0,0 +1,148 @@
+package querybuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+type compound struct {
+	operator SetOperator
+	query    Subquery
+}
+
+// Combine the result of the select with the one of another select,
+// any number of them can be chained. ORDER BY and LIMIT of the
+// builder apply to the combined result, so the queries combined
+// into it must not have their own.
+// INTERSECT and EXCEPT need MySQL 8.0.31 or later.
+// Usage: customers.SelectByColumns([]string{"email"}).UnionAll(leads.SelectByColumns([]string{"email"}))
+func (d *DbAdapter) Union(query Subquery) *DbAdapter {
+	return d.combine(Union, query)
+}
+
+func (d *DbAdapter) UnionAll(query Subquery) *DbAdapter {
+	return d.combine(UnionAll, query)
+}
+
+func (d *DbAdapter) Intersect(query Subquery) *DbAdapter {
+	return d.combine(Intersect, query)
+}
+
+func (d *DbAdapter) Except(query Subquery) *DbAdapter {
+	return d.combine(Except, query)
+}
+
+func (d *DbAdapter) combine(operator SetOperator, query Subquery) *DbAdapter {
+	d.compounds = append(d.compounds, compound{operator: operator, query: query})
+	return d
+}
+
+func (d *DbAdapter) initBuildCompounds(st *statement) error {
+	if len(d.compounds) == 0 {
+		return nil
+	}
+
+	if d.lockMode != "" {
+		return fmt.Errorf("Locking reads cannot be combined with %s.", d.compounds[0].operator)
+	}
+
+	columnCount, countKnown := d.selectColumnCount()
+
+	for i := 0; i < len(d.compounds); i++ {
+		part := d.compounds[i]
+
+		branch, err := compoundBranch(part.query)
+		if err != nil {
+			return err
+		}
+
+		// Branches written as "*" can only be checked by the database
+		if branchCount, branchCountKnown := branch.selectColumnCount(); countKnown && branchCountKnown && branchCount != columnCount {
+			return fmt.Errorf("Queries combined with %s must select the same number of columns, expected %d but got %d.", part.operator, columnCount, branchCount)
+		}
+
+		subSt, err := buildSubquery(part.query)
+		if err != nil {
+			return err
+		}
+		st.concatenate(fmt.Sprintf("%s %s", part.operator, subSt.query))
+		st.addStatementArgs(subSt)
+	}
+	return nil
+}
+
+// The builder behind a query combined with a set operator, it is
+// rendered without parentheses which not every database accepts,
+// hence everything that would need them is rejected
+func compoundBranch(query Subquery) (*DbAdapter, error) {
+	var branch *DbAdapter
+	switch q := query.(type) {
+	case *DbAdapter:
+		branch = q
+	case interface{ Builder() *DbAdapter }:
+		branch = q.Builder()
+	default:
+		return nil, fmt.Errorf("Unsupported query to combine: %T.", query)
+	}
+	if branch == nil {
+		return nil, fmt.Errorf("Query to combine is nil.")
+	}
+
+	if len(branch.orderBy) != 0 || branch.queryLimit.Limit > 0 {
+		return nil, fmt.Errorf("Combined queries cannot have their own ORDER BY or LIMIT, set them on the first query.")
+	}
+	if branch.lockMode != "" {
+		return nil, fmt.Errorf("Combined queries cannot have a locking clause.")
+	}
+	if len(branch.compounds) != 0 {
+		return nil, fmt.Errorf("Combined queries cannot be combined themselves, chain the set operators on the first query.")
+	}
+	return branch, nil
+}
+
+// Number of columns the select returns, unknown if
+// it selects "*" or "table.*"
+func (d *DbAdapter) selectColumnCount() (int, bool) {
+	if len(d.queryColumns) == 0 {
+		return 0, false
+	}
+
+	count := len(d.selectSubqueries)
+	for i := 0; i < len(d.queryColumns); i++ {
+		columns := splitTopLevel(d.queryColumns[i], ',')
+		for j := 0; j < len(columns); j++ {
+			if strings.HasSuffix(strings.TrimSpace(columns[j]), "*") {
+				return 0, false
+			}
+		}
+		count += len(columns)
+	}
+	return count, true
+}
+
+// Split on separator outside of parentheses and quotes,
+// "CONCAT(a, b), c" is two columns
+func splitTopLevel(s string, separator rune) []string {
+	parts := []string{}
+	depth := 0
+	var quote rune
+	start := 0
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == separator && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
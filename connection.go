@@ -0,0 +1,156 @@
+package querybuilder
+
+import (
+	"database/sql"
+	"sync"
+)
+
+// connection is shared by an adapter and every query builder
+// handed out by it through Table and NewQuery, it holds no
+// query state and is safe for concurrent use
+type connection struct {
+	mu                  sync.RWMutex
+	_db                 *sql.DB
+	isConnectedToServer bool
+	dbCredentials       Credentials
+	dialect             Dialect
+	strictScan          bool
+	strictIdentifiers   bool
+	autoFieldPrefix     bool
+	stripFieldPrefix    bool
+}
+
+func (c *connection) getDb() *sql.DB {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c._db
+}
+
+func (c *connection) setDb(db *sql.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Make sure connected to sql server
+	c._db = db
+	c.isConnectedToServer = db != nil
+}
+
+func (c *connection) setDisconnected() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.isConnectedToServer = false
+}
+
+func (c *connection) isConnected() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.isConnectedToServer
+}
+
+func (c *connection) getCredentials() Credentials {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dbCredentials
+}
+
+func (c *connection) setCredentials(credentials Credentials) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dbCredentials = credentials
+}
+
+func (c *connection) getDialect() Dialect {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.dialect == nil {
+		return MySQL
+	}
+	return c.dialect
+}
+
+func (c *connection) setDialect(dialect Dialect) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dialect = dialect
+}
+
+func (c *connection) isStrictScan() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.strictScan
+}
+
+func (c *connection) setStrictScan(strict bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.strictScan = strict
+}
+
+func (c *connection) isStrictIdentifiers() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.strictIdentifiers
+}
+
+func (c *connection) setStrictIdentifiers(strict bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.strictIdentifiers = strict
+}
+
+func (c *connection) isAutoFieldPrefix() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.autoFieldPrefix
+}
+
+func (c *connection) setAutoFieldPrefix(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.autoFieldPrefix = enabled
+}
+
+func (c *connection) isStripFieldPrefix() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stripFieldPrefix
+}
+
+func (c *connection) setStripFieldPrefix(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stripFieldPrefix = enabled
+}
+
+// Lazily create the connection, so that a zero
+// DbAdapter can still be used with Connect or
+// InitWithoutConnection. This must happen before
+// the adapter is shared between goroutines.
+func (d *DbAdapter) connection() *connection {
+	if d.conn == nil {
+		d.conn = &connection{}
+	}
+	return d.conn
+}
+
+// Returns a new query builder for table that shares the
+// connection (and transaction, if any) of d but none of its
+// query state. Builders must not be shared between goroutines,
+// the adapter they come from can be.
+// Usage: d.Table("users").Select().Where(...).ExecSelect()
+func (d *DbAdapter) Table(table string) *DbAdapter {
+	return d.newQueryBuilder(TableDetails{Table: table})
+}
+
+// Same as Table, for the table and prefix of d
+func (d *DbAdapter) NewQuery() *DbAdapter {
+	return d.newQueryBuilder(TableDetails{Table: d.dbTable, Prefix: d.dbTableFieldPrefix})
+}
+
+func (d *DbAdapter) newQueryBuilder(table TableDetails) *DbAdapter {
+	builder := &DbAdapter{
+		conn:        d.connection(),
+		transaction: d.transaction,
+	}
+	builder.SetTableAndPrefix(table)
+	return builder
+}
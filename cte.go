@@ -0,0 +1,130 @@
+package querybuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+type commonTableExpression struct {
+	name      string
+	query     Subquery
+	raw       string
+	args      []interface{}
+	recursive bool
+}
+
+// Declare a named common table expression, the statement
+// can use its name like any table, e.g. with Table or Join.
+// The name can carry a column list: "tree (id, depth)"
+// Usage: d.Table("recent").With("recent", d.Table("orders").Select().WhereCondition(...)).Select()
+func (d *DbAdapter) With(name string, query Subquery) *DbAdapter {
+	return d.addCommonTableExpression(commonTableExpression{name: name, query: query})
+}
+
+// A CTE referencing itself, usually an anchor select
+// combined with UnionAll with a select joining name.
+// Any recursive CTE turns the whole list into WITH RECURSIVE
+func (d *DbAdapter) WithRecursive(name string, query Subquery) *DbAdapter {
+	return d.addCommonTableExpression(commonTableExpression{name: name, query: query, recursive: true})
+}
+
+// Same as With, for SQL written by hand with "?" placeholders
+// Usage: d.WithRaw("tree (id, depth)", "SELECT id, 0 FROM categories WHERE id = ? UNION ALL ...", rootId)
+func (d *DbAdapter) WithRaw(name string, query string, args ...interface{}) *DbAdapter {
+	return d.addCommonTableExpression(commonTableExpression{name: name, raw: query, args: args})
+}
+
+// Same as WithRaw for a recursive CTE
+func (d *DbAdapter) WithRecursiveRaw(name string, query string, args ...interface{}) *DbAdapter {
+	return d.addCommonTableExpression(commonTableExpression{name: name, raw: query, args: args, recursive: true})
+}
+
+func (d *DbAdapter) addCommonTableExpression(cte commonTableExpression) *DbAdapter {
+	d.commonTableExpressions = append(d.commonTableExpressions, cte)
+	return d
+}
+
+// Render the CTEs ahead of the statement, their
+// values come before the ones of the statement
+func (d *DbAdapter) prependCommonTableExpressions(st statement) (statement, error) {
+	if len(d.commonTableExpressions) == 0 {
+		return st, nil
+	}
+
+	if d.queryType == queryTypeInsert {
+		return st, fmt.Errorf("Common table expressions are not supported for INSERT.")
+	}
+
+	keyword := "WITH"
+	names := map[string]bool{}
+	expressions := []string{}
+	withSt := statement{}
+
+	for i := 0; i < len(d.commonTableExpressions); i++ {
+		cte := d.commonTableExpressions[i]
+
+		name := strings.TrimSpace(cte.name)
+		if name == "" {
+			return st, fmt.Errorf("Common table expression needs a name.")
+		}
+		// Only the name itself has to be unique, not the column list
+		tableName := strings.TrimSpace(strings.SplitN(name, "(", 2)[0])
+		if names[tableName] {
+			return st, fmt.Errorf("Common table expression %s is declared twice.", tableName)
+		}
+		names[tableName] = true
+
+		if cte.recursive {
+			keyword = "WITH RECURSIVE"
+		}
+
+		query := cte.raw
+		args := cte.args
+		if cte.query != nil {
+			subSt, err := buildSubquery(cte.query)
+			if err != nil {
+				return st, err
+			}
+			query = subSt.query
+			args = subSt.args
+			withSt.hasPotentialThreat = withSt.hasPotentialThreat || subSt.hasPotentialThreat
+		}
+		if strings.TrimSpace(query) == "" {
+			return st, fmt.Errorf("Common table expression %s has no query.", tableName)
+		}
+
+		renderedName, err := d.renderCommonTableExpressionName(&withSt, name)
+		if err != nil {
+			return st, err
+		}
+
+		expressions = append(expressions, fmt.Sprintf("%s %s (%s)", renderedName, As, query))
+		withSt.addArgs(args...)
+	}
+
+	withSt.concatenate(fmt.Sprintf("%s %s", keyword, strings.Join(expressions, ", ")))
+	withSt.concatenate(st.query)
+	withSt.addStatementArgs(st)
+	return withSt, nil
+}
+
+// "tree (id, depth)" with every name quoted
+func (d *DbAdapter) renderCommonTableExpressionName(st *statement, name string) (string, error) {
+	parts := strings.SplitN(name, "(", 2)
+	tableName, err := d.renderAlias(st, parts[0])
+	if err != nil || len(parts) == 1 {
+		return tableName, err
+	}
+
+	columnList := strings.TrimSpace(parts[1])
+	if !strings.HasSuffix(columnList, ")") {
+		return d.renderUnvalidated(st, name)
+	}
+	columns := strings.Split(strings.TrimSuffix(columnList, ")"), ",")
+	for i := 0; i < len(columns); i++ {
+		if columns[i], err = d.renderAlias(st, columns[i]); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%s (%s)", tableName, strings.Join(columns, ", ")), nil
+}
@@ -1,6 +1,7 @@
 package querybuilder
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -9,49 +10,82 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
+// DbAdapter is both the handle to the database and a query
+// builder. The builder state is not safe for concurrent use,
+// goroutines sharing an adapter should build their queries
+// on the independent builders returned by Table or NewQuery.
 type DbAdapter struct {
-	_db                                    *sql.DB
-	isConnectedToServer                    bool
-	dbTable                                string
-	dbTableFieldPrefix                     string
-	queryType                              queryType
-	queryColumns                           []string
-	queryValues                            []interface{}
-	queryString                            string
-	dbCredentials                          Credentials
-	queryHasPotentialThreat                bool
-	whereClauses                           []Where
-	clauseValues                           []interface{}
-	queryAggregatedValuesPreparedStatement []interface{}
-	orderBy                                []OrderBy
-	groupBy                                []string
-	queryLimit                             limitParams
-	joins                                  []join
-	lastExecutedQuery                      string
-	// havingClausesAnd                       []Clause
-	// havingClausesOr                        []Clause
-	// havingClausesNot                       []Clause
-}
-
-func (d *DbAdapter) Connect(dbCredentials Credentials, table TableDetails) {
-
-	d.dbCredentials = dbCredentials
+	conn               *connection
+	transaction        *transaction
+	dbTable            string
+	dbTableFieldPrefix string
+	lastExecutedQuery  string
+	// Set when the statement was built, see HasPotentialThreat
+	queryHasPotentialThreat bool
+	queryModel
+}
+
+// queryModel is the structured model of the statement being
+// built. Each part is rendered in canonical SQL order by
+// buildStatement, whatever order the builder methods were
+// called in, and carries its own values so the arguments
+// line up with the placeholders.
+type queryModel struct {
+	queryType              queryType
+	commonTableExpressions []commonTableExpression
+	queryColumns           []string
+	queryColumnArgs        []interface{}
+	selectSubqueries       []selectSubquery
+	fromSubquery           *selectSubquery
+	tableAlias             string
+	queryValues            []interface{}
+	rawExpressions         []rawExpression
+	joins                  []join
+	deleteTables           []string
+	whereClauses           []Where
+	groupBy                []string
+	groupByArgs            []interface{}
+	havingClauses          []Where
+	compounds              []compound
+	orderBy                []OrderBy
+	queryLimit             limitParams
+	lockMode               LockMode
+	insertMode             InsertMode
+	upsertConflictColumns  []string
+	upsertColumns          []UpsertColumn
+	// Values of the Make* helpers not yet taken by Where or Having
+	clauseValues []interface{}
+	queryError   error
+}
+
+func (d *DbAdapter) Connect(dbCredentials Credentials, table TableDetails) error {
+	return d.ConnectContext(context.Background(), dbCredentials, table)
+}
+
+// The context only bounds the initial ping, the
+// connection pool itself outlives it
+func (d *DbAdapter) ConnectContext(ctx context.Context, dbCredentials Credentials, table TableDetails) error {
+
 	d.MakeServerCredentials(dbCredentials)
+	credentials := d.connection().getCredentials()
+	dialect := d.GetDialect()
 
 	// Initialize connection string.
-	var connectionString = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?allowNativePasswords=true&tls=%t", d.dbCredentials.User, d.dbCredentials.Password, d.dbCredentials.Host, d.dbCredentials.Port, d.dbCredentials.Database, d.dbCredentials.Tls) // tls is often false
+	var connectionString = dialect.DataSourceName(credentials)
 
-	db, err := sql.Open("mysql", connectionString)
+	db, err := sql.Open(dialect.DriverName(), connectionString)
 	if err != nil {
-		d.handleConnectionError(err)
+		return d.handleConnectionError(err)
 	}
 
-	if err = db.Ping(); err != nil {
-		d.handleConnectionError(err)
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = &ContextError{Err: ctxErr, DriverErr: err}
+		}
+		return d.handleConnectionError(err)
 	}
 
-	d._db = db
-
 	noOfConnection := 10
 	maxOpenConnections := noOfConnection
 	maxIdleConnections := noOfConnection
@@ -64,22 +98,24 @@ func (d *DbAdapter) Connect(dbCredentials Credentials, table TableDetails) {
 		maxIdleConnections = dbCredentials.MaxIdleConnections
 	}
 
-	d._db.SetMaxOpenConns(maxOpenConnections)
-	d._db.SetMaxIdleConns(maxIdleConnections)
+	db.SetMaxOpenConns(maxOpenConnections)
+	db.SetMaxIdleConns(maxIdleConnections)
 	db.SetConnMaxLifetime(time.Minute * 3)
 	d.SetTableAndPrefix(table)
 
-	d.isConnectedToServer = true
+	d.connection().setDb(db)
+	return nil
 }
 
 func (d *DbAdapter) PrintDBDetails() {
+	credentials := d.connection().getCredentials()
 
 	fmt.Println("+++++++++++++ Database Details +++++++++++++")
 	fmt.Println("============================================")
-	fmt.Printf("Host: %s\n", d.dbCredentials.Host)
-	fmt.Printf("Port: %s\n", d.dbCredentials.Port)
-	fmt.Printf("User Name: %s\n", d.dbCredentials.User)
-	fmt.Printf("Database Name: %s\n", d.dbCredentials.Database)
+	fmt.Printf("Host: %s\n", credentials.Host)
+	fmt.Printf("Port: %s\n", credentials.Port)
+	fmt.Printf("User Name: %s\n", credentials.User)
+	fmt.Printf("Database Name: %s\n", credentials.Database)
 	fmt.Println("============================================")
 	fmt.Println()
 }
@@ -94,17 +130,17 @@ func (d *DbAdapter) InitWithoutConnection(db *sql.DB, table TableDetails) {
 }
 
 func (d *DbAdapter) GetSqlConnection() *sql.DB {
-	return d._db
+	return d.connection().getDb()
 }
 
 func (d *DbAdapter) SetSqlConnection(db *sql.DB) {
-	// Make sure connected to sql server
-	if db == nil {
-		d.isConnectedToServer = false
-	} else {
-		d.isConnectedToServer = true
-	}
-	d._db = db
+	d.connection().setDb(db)
+}
+
+// Returns true once Connect succeeded or a
+// connection was set with SetSqlConnection
+func (d *DbAdapter) IsConnected() bool {
+	return d.connection().isConnected()
 }
 
 // Set the table and its preifx, if you
@@ -143,80 +179,31 @@ func (d *DbAdapter) GetDbTableFieldPrefix() string {
 // 	dbPassword string) *Credentials {
 func (d *DbAdapter) MakeServerCredentials(credentials Credentials) {
 
-	host := ""
 	if credentials.Host == "" {
-		host = "localhost"
+		credentials.Host = "localhost"
 	}
 
-	port := ""
 	if credentials.Port == "" {
-		port = "3306"
+		credentials.Port = d.GetDialect().DefaultPort()
 	}
 
-	// tls := ""
-	// if credentials.Tls == "" {
-	// 	tls = false
-	// }
-
-	// var dbCredentials = new(Credentials)
-	credentials.Host = host
-	credentials.Port = port
-	// credentials.Tls = tls
-
-	d.dbCredentials = credentials
+	d.connection().setCredentials(credentials)
 
 }
 
-func (d *DbAdapter) handleConnectionError(err error) {
+func (d *DbAdapter) handleConnectionError(err error) error {
 
-	d.isConnectedToServer = false
+	d.connection().setDisconnected()
 
-	if err != nil {
-		fmt.Println(err)
-	}
-	// Finally panic
-	panic("Oops! DB connection could be established")
+	return fmt.Errorf("Oops! DB connection could not be established: %w", err)
 }
 
 func (d *DbAdapter) unsetQueryParams() {
+	d.queryModel = queryModel{}
+}
 
-	// _db                       *sql.DB
-	// isConnectedToServer       bool
-	// dbTable                   string
-	// dbTableFieldPrefix        string
-	// queryType                 queryType
-	// queryColumns              []string
-	// queryValues               []string
-	// queryString               string
-	// dbCredentials             Credentials
-	// queryHasPotentialThreat   bool
-	// whereClauses              []Where
-	// havingClausesAnd          []Clause
-	// havingClausesOr           []Clause
-	// havingClausesNot          []Clause
-	// queryPreparedClauseValues []interface{}
-	// orderBy                   []OrderBy
-	// groupBy                   []string
-	// queryLimit                limitParams
-	// joins                     []join
-	d.queryType = ""
-	d.queryColumns = nil
-	d.queryValues = nil
-	d.queryString = ""
-	d.queryHasPotentialThreat = false
-	d.whereClauses = nil
-	d.clauseValues = nil
-	d.queryAggregatedValuesPreparedStatement = nil
-	d.orderBy = nil
-	d.groupBy = nil
-	d.queryLimit = limitParams{}
-	d.joins = nil
-	// d.lastExecutedQuery = ""
-
-}
-
-func (d *DbAdapter) setLastExecutedQuery() {
-	d.lastExecutedQuery = fmt.Sprintf("\n%s, %v\n", d.queryString, d.queryAggregatedValuesPreparedStatement)
+func (d *DbAdapter) setLastExecutedQuery(st statement) {
+	d.lastExecutedQuery = fmt.Sprintf("\n%s, %v\n", st.query, st.args)
 }
 
 func (d *DbAdapter) PrintLastExecutedQuery() {
@@ -0,0 +1,42 @@
+package querybuilder
+
+import "fmt"
+
+// Delete the matching rows of several tables of the statement,
+// the tables are named by their alias if they have one. Delete
+// alone removes the rows of the table of the builder only,
+// joins are then used to find the rows. MySQL only, and
+// neither can have ORDER BY or LIMIT.
+// Usage: d.Table("orders").As("o").JoinOnCondition(InnerJoin, "order_items", "i", Col("i.order_id").EqCol("o.id")).DeleteTables("o", "i")
+func (d *DbAdapter) DeleteTables(tables ...string) *DbAdapter {
+	d.queryType = queryTypeDelete
+	d.deleteTables = append(d.deleteTables, tables...)
+	return d
+}
+
+// The tables to delete from, each one has to be part of the statement
+func (d *DbAdapter) deleteTargets() ([]string, error) {
+	main := d.dbTable
+	if d.tableAlias != "" {
+		main = d.tableAlias
+	}
+	if len(d.deleteTables) == 0 {
+		return []string{main}, nil
+	}
+
+	known := map[string]bool{main: true}
+	for i := 0; i < len(d.joins); i++ {
+		if d.joins[i].Alias != "" {
+			known[d.joins[i].Alias] = true
+		} else if d.joins[i].subquery == nil {
+			known[d.joins[i].ForignTable] = true
+		}
+	}
+
+	for i := 0; i < len(d.deleteTables); i++ {
+		if !known[d.deleteTables[i]] {
+			return nil, fmt.Errorf("Cannot delete from %s, it is not a table of the statement.", d.deleteTables[i])
+		}
+	}
+	return d.deleteTables, nil
+}
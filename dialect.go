@@ -0,0 +1,393 @@
+package querybuilder
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Dialect renders the parts of a statement that differ between
+// database servers. Statements are built with "?" placeholders
+// and rewritten with Placeholder once they are complete.
+// MySQL is used unless another dialect is set with SetDialect.
+type Dialect interface {
+	Name() string
+	// The database/sql driver used by Connect, it has
+	// to be imported by the application, except for MySQL
+	DriverName() string
+	DataSourceName(credentials Credentials) string
+	DefaultPort() string
+	// index starts at 1
+	Placeholder(index int) string
+	// Most placeholders a single statement may hold
+	MaxPlaceholders() int
+	QuoteIdentifier(identifier string) string
+	Limit(limit, offset int) string
+	// LIMIT for UPDATE and DELETE, which take no offset
+	WriteLimit(limit int) (string, error)
+	// Row locking clause of a select
+	Lock(mode LockMode) (string, error)
+	// Returns the format of the function, see MakeMySQLFunction
+	Function(function MySqlFunction) string
+	// Full text search, see MakeMatchAgainstColumn
+	MatchColumn(columns []string) string
+	MatchSearchTerm(placeholder string) string
+	// INSERT INTO, INSERT IGNORE INTO... for the mode and, where
+	// the verb cannot express it, the clause following the VALUES
+	InsertVerb(mode InsertMode) (string, string, error)
+	// The clause following the VALUES of an insert, which
+	// updates the existing row on a duplicate key, table and
+	// columns are quoted already
+	Upsert(table string, conflictColumns []string, updates []UpsertColumn) (string, []interface{}, error)
+}
+
+var (
+	MySQL Dialect = mysqlDialect{}
+	// MySQL 8.0.19 and later, refers to the new values of an
+	// upsert by row alias rather than the deprecated VALUES()
+	MySQL8     Dialect = mysqlDialect{useRowAlias: true}
+	PostgreSQL Dialect = postgresDialect{}
+	SQLite     Dialect = sqliteDialect{}
+)
+
+// Set the dialect before calling Connect, it is shared
+// by every builder handed out by the adapter
+func (d *DbAdapter) SetDialect(dialect Dialect) {
+	d.connection().setDialect(dialect)
+}
+
+func (d *DbAdapter) GetDialect() Dialect {
+	return d.connection().getDialect()
+}
+
+type mysqlDialect struct {
+	useRowAlias bool
+}
+
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (mysqlDialect) DriverName() string {
+	return "mysql"
+}
+
+func (mysqlDialect) DataSourceName(credentials Credentials) string {
+	// tls is often false
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?allowNativePasswords=true&tls=%t", credentials.User, credentials.Password, credentials.Host, credentials.Port, credentials.Database, credentials.Tls)
+}
+
+func (mysqlDialect) DefaultPort() string {
+	return "3306"
+}
+
+func (mysqlDialect) Placeholder(index int) string {
+	return preparationPlaceHolder
+}
+
+func (mysqlDialect) MaxPlaceholders() int {
+	return 65535
+}
+
+func (mysqlDialect) QuoteIdentifier(identifier string) string {
+	return quoteIdentifierWith(identifier, "`")
+}
+
+func (mysqlDialect) Limit(limit, offset int) string {
+	return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+}
+
+func (mysqlDialect) WriteLimit(limit int) (string, error) {
+	return fmt.Sprintf("LIMIT %d", limit), nil
+}
+
+func (mysqlDialect) Lock(mode LockMode) (string, error) {
+	if mode == LockForShare {
+		// FOR SHARE is MySQL 8 only
+		return "LOCK IN SHARE MODE", nil
+	}
+	return string(mode), nil
+}
+
+func (mysqlDialect) Function(function MySqlFunction) string {
+	return string(function)
+}
+
+func (mysqlDialect) MatchColumn(columns []string) string {
+	return fmt.Sprintf(Match, strings.Join(columns, ", "))
+}
+
+func (mysqlDialect) MatchSearchTerm(placeholder string) string {
+	return fmt.Sprintf(Against, placeholder)
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) DriverName() string {
+	return "postgres"
+}
+
+func (postgresDialect) DataSourceName(credentials Credentials) string {
+	sslMode := "disable"
+	if credentials.Tls {
+		sslMode = "require"
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(credentials.User, credentials.Password),
+		Host:     fmt.Sprintf("%s:%s", credentials.Host, credentials.Port),
+		Path:     credentials.Database,
+		RawQuery: fmt.Sprintf("sslmode=%s", sslMode),
+	}
+	return dsn.String()
+}
+
+func (postgresDialect) DefaultPort() string {
+	return "5432"
+}
+
+func (postgresDialect) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
+
+func (postgresDialect) MaxPlaceholders() int {
+	return 65535
+}
+
+func (postgresDialect) QuoteIdentifier(identifier string) string {
+	return quoteIdentifierWith(identifier, `"`)
+}
+
+func (postgresDialect) Limit(limit, offset int) string {
+	return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+}
+
+func (postgresDialect) WriteLimit(limit int) (string, error) {
+	return "", fmt.Errorf("LIMIT is not supported in UPDATE and DELETE statements by postgres.")
+}
+
+func (postgresDialect) Lock(mode LockMode) (string, error) {
+	return string(mode), nil
+}
+
+func (postgresDialect) Function(function MySqlFunction) string {
+	switch function {
+	case Year:
+		return "EXTRACT(YEAR FROM %s)"
+	}
+	return string(function)
+}
+
+func (postgresDialect) MatchColumn(columns []string) string {
+	return fmt.Sprintf("to_tsvector(concat_ws(' ', %s))", strings.Join(columns, ", "))
+}
+
+func (postgresDialect) MatchSearchTerm(placeholder string) string {
+	return fmt.Sprintf("@@ plainto_tsquery(%s)", placeholder)
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (sqliteDialect) DriverName() string {
+	return "sqlite3"
+}
+
+// Credentials.Database is the path of the database file
+func (sqliteDialect) DataSourceName(credentials Credentials) string {
+	return credentials.Database
+}
+
+func (sqliteDialect) DefaultPort() string {
+	return ""
+}
+
+func (sqliteDialect) Placeholder(index int) string {
+	return preparationPlaceHolder
+}
+
+func (sqliteDialect) MaxPlaceholders() int {
+	return 32766
+}
+
+func (sqliteDialect) QuoteIdentifier(identifier string) string {
+	return quoteIdentifierWith(identifier, `"`)
+}
+
+func (sqliteDialect) Limit(limit, offset int) string {
+	return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+}
+
+func (sqliteDialect) WriteLimit(limit int) (string, error) {
+	// Only available if SQLite was compiled with
+	// SQLITE_ENABLE_UPDATE_DELETE_LIMIT
+	return fmt.Sprintf("LIMIT %d", limit), nil
+}
+
+func (sqliteDialect) Lock(mode LockMode) (string, error) {
+	return "", fmt.Errorf("Row locking is not supported by sqlite, the whole database is locked by a write transaction.")
+}
+
+func (sqliteDialect) Function(function MySqlFunction) string {
+	switch function {
+	case Now:
+		return "CURRENT_TIMESTAMP"
+	case Year:
+		return "CAST(strftime('%%Y', %s) AS INTEGER)"
+	}
+	return string(function)
+}
+
+// The column of a FTS5 table, or the table itself
+// to search all its columns
+func (sqliteDialect) MatchColumn(columns []string) string {
+	return strings.Join(columns, ", ")
+}
+
+func (sqliteDialect) MatchSearchTerm(placeholder string) string {
+	return fmt.Sprintf("MATCH %s", placeholder)
+}
+
+func (mysqlDialect) InsertVerb(mode InsertMode) (string, string, error) {
+	return string(mode), "", nil
+}
+
+func (m mysqlDialect) Upsert(table string, conflictColumns []string, updates []UpsertColumn) (string, []interface{}, error) {
+	clause := "ON DUPLICATE KEY UPDATE"
+	newValue := "VALUES(%s)"
+	if m.useRowAlias {
+		clause = "AS new ON DUPLICATE KEY UPDATE"
+		newValue = "new.%s"
+	}
+	assignments, args, err := upsertAssignments(updates, "%s", newValue, true)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s %s", clause, strings.Join(assignments, ", ")), args, nil
+}
+
+func (postgresDialect) InsertVerb(mode InsertMode) (string, string, error) {
+	switch mode {
+	case ModeInsertIgnore:
+		return string(ModeInsert), "ON CONFLICT DO NOTHING", nil
+	case ModeReplace:
+		return "", "", fmt.Errorf("REPLACE is not supported by postgres, use OnDuplicateKeyUpdate.")
+	}
+	return string(mode), "", nil
+}
+
+func (p postgresDialect) Upsert(table string, conflictColumns []string, updates []UpsertColumn) (string, []interface{}, error) {
+	return onConflictDoUpdate(table+".%s", conflictColumns, updates)
+}
+
+func (sqliteDialect) InsertVerb(mode InsertMode) (string, string, error) {
+	switch mode {
+	case ModeInsertIgnore:
+		return "INSERT OR IGNORE INTO", "", nil
+	case ModeReplace:
+		return "INSERT OR REPLACE INTO", "", nil
+	}
+	return string(mode), "", nil
+}
+
+func (sqliteDialect) Upsert(table string, conflictColumns []string, updates []UpsertColumn) (string, []interface{}, error) {
+	return onConflictDoUpdate("%s", conflictColumns, updates)
+}
+
+// ON CONFLICT (...) DO UPDATE SET ..., shared by PostgreSQL and SQLite
+func onConflictDoUpdate(existingValue string, conflictColumns []string, updates []UpsertColumn) (string, []interface{}, error) {
+	if len(conflictColumns) == 0 {
+		return "", nil, fmt.Errorf("ON CONFLICT needs the columns of the unique key.")
+	}
+	assignments, args, err := upsertAssignments(updates, existingValue, "EXCLUDED.%s", false)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(assignments) == 0 {
+		return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", strings.Join(conflictColumns, ", ")), nil, nil
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(conflictColumns, ", "), strings.Join(assignments, ", ")), args, nil
+}
+
+// column = value pairs of an upsert. existingValue and newValue
+// are formats taking the column name. KeepExisting columns are
+// only assigned to themselves if assignKept is set, MySQL has no
+// other way to express them.
+func upsertAssignments(updates []UpsertColumn, existingValue, newValue string, assignKept bool) ([]string, []interface{}, error) {
+	assignments := []string{}
+	args := []interface{}{}
+	for _, update := range updates {
+		column := update.Column
+		existing := fmt.Sprintf(existingValue, column)
+		switch update.Action {
+		case UseNewValue:
+			assignments = append(assignments, fmt.Sprintf("%s %s %s", column, Equal, fmt.Sprintf(newValue, column)))
+		case Increment:
+			increment := update.Value
+			if increment == nil {
+				increment = 1
+			}
+			assignments = append(assignments, fmt.Sprintf("%s %s %s + %s", column, Equal, existing, preparationPlaceHolder))
+			args = append(args, increment)
+		case SetValue:
+			assignments = append(assignments, fmt.Sprintf("%s %s %s", column, Equal, preparationPlaceHolder))
+			args = append(args, update.Value)
+		case KeepExisting:
+			if assignKept {
+				assignments = append(assignments, fmt.Sprintf("%s %s %s", column, Equal, existing))
+			}
+		default:
+			return nil, nil, fmt.Errorf("Unknown upsert action %q for column %s.", update.Action, column)
+		}
+	}
+	return assignments, args, nil
+}
+
+// Quote every part of a table.column identifier,
+// quote characters inside the name are doubled
+func quoteIdentifierWith(identifier, quote string) string {
+	parts := strings.Split(identifier, ".")
+	for i := 0; i < len(parts); i++ {
+		if parts[i] == "*" {
+			continue
+		}
+		parts[i] = quote + strings.ReplaceAll(parts[i], quote, quote+quote) + quote
+	}
+	return strings.Join(parts, ".")
+}
+
+// Replace the "?" placeholders of a built statement with the
+// ones of the dialect, placeholders in quoted strings and
+// identifiers are left alone
+func rebindPlaceholders(query string, dialect Dialect) string {
+	if dialect.Placeholder(1) == preparationPlaceHolder {
+		return query
+	}
+
+	var rebound strings.Builder
+	var quote rune
+	index := 0
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			index++
+			rebound.WriteString(dialect.Placeholder(index))
+			continue
+		}
+		rebound.WriteRune(r)
+	}
+	return rebound.String()
+}
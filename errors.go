@@ -0,0 +1,61 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrNoRows is returned by ExecSelectRow when the query matched no row,
+// it is the same value as sql.ErrNoRows so errors.Is works with either.
+var ErrNoRows = sql.ErrNoRows
+
+// ErrMultipleRows is returned by ExecSelectRow when more than one row matched.
+var ErrMultipleRows = errors.New("Query returned more than one row.")
+
+// QueryError wraps an error returned by the database together with
+// the statement that caused it, so that callers can log the query
+// without relying on PrintLastExecutedQuery.
+type QueryError struct {
+	Query string
+	Args  []interface{}
+	Err   error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%v (query: %s, %v)", e.Err, e.Query, e.Args)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// ContextError is used when a query was aborted because its context
+// was cancelled or its deadline passed. errors.Is(err, context.Canceled)
+// and errors.Is(err, context.DeadlineExceeded) work on it as well.
+type ContextError struct {
+	Err       error
+	DriverErr error
+}
+
+func (e *ContextError) Error() string {
+	if e.DriverErr == nil || e.DriverErr == e.Err {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Err, e.DriverErr)
+}
+
+func (e *ContextError) Unwrap() error {
+	return e.Err
+}
+
+// IsContextError reports whether err was caused by a cancelled
+// context or an exceeded deadline rather than by the database.
+func IsContextError(err error) bool {
+	var contextError *ContextError
+	if errors.As(err, &contextError) {
+		return true
+	}
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
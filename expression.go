@@ -0,0 +1,213 @@
+package querybuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The expression builder makes conditions that carry their
+// own values, nothing is collected on the adapter, so they
+// can be made anywhere and in any order.
+// Usage:
+//
+//	d.Select().WhereCondition(
+//		querybuilder.Col("age").Gte(18).And(querybuilder.Col("status").In("a", "b")),
+//	)
+//
+// Conditions of both kinds cannot be mixed in one group,
+// building the statement fails if they are.
+
+type ColumnExpr struct {
+	column string
+}
+
+func Col(column string) ColumnExpr {
+	return ColumnExpr{column: column}
+}
+
+func (c ColumnExpr) Eq(value interface{}) Clause {
+	return c.compare(Equal, value)
+}
+
+func (c ColumnExpr) NotEq(value interface{}) Clause {
+	return c.compare(NotEqual, value)
+}
+
+func (c ColumnExpr) Gt(value interface{}) Clause {
+	return c.compare(GreaterThan, value)
+}
+
+func (c ColumnExpr) Gte(value interface{}) Clause {
+	return c.compare(GreaterThanEqualTo, value)
+}
+
+func (c ColumnExpr) Lt(value interface{}) Clause {
+	return c.compare(LessThan, value)
+}
+
+func (c ColumnExpr) Lte(value interface{}) Clause {
+	return c.compare(LessThanEqualTo, value)
+}
+
+func (c ColumnExpr) Like(pattern interface{}) Clause {
+	return c.compare(Like, pattern)
+}
+
+func (c ColumnExpr) NotLike(pattern interface{}) Clause {
+	return c.compare(NotLike, pattern)
+}
+
+func (c ColumnExpr) IsNull() Clause {
+	return Clause{ClauseLogic: AND, Column: c.column, ValueAggregatedWithOperator: IsNull}
+}
+
+func (c ColumnExpr) IsNotNull() Clause {
+	return Clause{ClauseLogic: AND, Column: c.column, ValueAggregatedWithOperator: IsNotNull}
+}
+
+func (c ColumnExpr) Between(rangeBegin, rangeEnd interface{}) Clause {
+	return Clause{
+		ClauseLogic:                 AND,
+		Column:                      c.column,
+		ValueAggregatedWithOperator: fmt.Sprintf("%s %s %s %s", Between, preparationPlaceHolder, AND, preparationPlaceHolder),
+		Args:                        []interface{}{rangeBegin, rangeEnd},
+	}
+}
+
+func (c ColumnExpr) In(values ...interface{}) Clause {
+	return c.inOrNotIn(In, values)
+}
+
+func (c ColumnExpr) NotIn(values ...interface{}) Clause {
+	return c.inOrNotIn(NotIn, values)
+}
+
+// Compare with another column instead of a value, mostly for
+// join conditions
+// Usage: Col("o.user_id").EqCol("u.id")
+func (c ColumnExpr) EqCol(column string) Clause {
+	return c.CompareCol(Equal, column)
+}
+
+func (c ColumnExpr) CompareCol(operator ClauseOperator, column string) Clause {
+	return Clause{ClauseLogic: AND, Column: c.column, ValueAggregatedWithOperator: string(operator), comparedColumn: column}
+}
+
+// value can be a Subquery returning a single value or a Raw expression
+func (c ColumnExpr) compare(operator ClauseOperator, value interface{}) Clause {
+	if subquery, ok := value.(Subquery); ok {
+		return Clause{ClauseLogic: AND, Column: c.column, ValueAggregatedWithOperator: string(operator), subquery: subquery}
+	}
+	if isRawExpression(value) {
+		return c.CompareCol(operator, value.(string))
+	}
+	return Clause{
+		ClauseLogic:                 AND,
+		Column:                      c.column,
+		ValueAggregatedWithOperator: fmt.Sprintf("%s %s", operator, preparationPlaceHolder),
+		Args:                        []interface{}{value},
+	}
+}
+
+// IN () is not valid SQL, an empty list matches
+// nothing for IN and everything for NOT IN
+func (c ColumnExpr) inOrNotIn(operator ClauseOperator, values []interface{}) Clause {
+	if len(values) == 0 {
+		if operator == In {
+			return Clause{ClauseLogic: AND, constant: "1 = 0"}
+		}
+		return Clause{ClauseLogic: AND, constant: "1 = 1"}
+	}
+
+	placeholderSlice := []string{}
+	for i := 0; i < len(values); i++ {
+		placeholderSlice = append(placeholderSlice, preparationPlaceHolder)
+	}
+	return Clause{
+		ClauseLogic:                 AND,
+		Column:                      c.column,
+		ValueAggregatedWithOperator: fmt.Sprintf("%s (%s)", operator, strings.Join(placeholderSlice, ", ")),
+		Args:                        values,
+	}
+}
+
+type MatchExpr struct {
+	columns []string
+}
+
+// Fulltext search, rendered by the dialect
+// Usage: querybuilder.MatchColumns("title", "body").Against("search term")
+func MatchColumns(columns ...string) MatchExpr {
+	return MatchExpr{columns: columns}
+}
+
+func (m MatchExpr) Against(searchTerm string) Clause {
+	return Clause{ClauseLogic: AND, fullTextColumns: m.columns, Args: []interface{}{searchTerm}}
+}
+
+func (c Clause) And(conditions ...Clause) Clause {
+	return combineConditions(AND, c, conditions)
+}
+
+func (c Clause) Or(conditions ...Clause) Clause {
+	return combineConditions(OR, c, conditions)
+}
+
+// Negate a condition, NOT (...)
+func Not(condition Clause) Clause {
+	condition.ClauseLogic = AND
+	return Clause{ClauseLogic: AND, Group: &Where{WhereLogic: NOT, Conditions: []Clause{condition}}}
+}
+
+// Join the conditions with logic into a nested group. A group
+// made with the same logic is extended instead of nested again,
+// so a.And(b).And(c) renders (a AND b AND c).
+func combineConditions(logic ClauseLogic, first Clause, others []Clause) Clause {
+	conditions := []Clause{}
+	if first.Group != nil && first.Group.WhereLogic == logic && isJoinedBy(logic, first.Group.Conditions) {
+		conditions = append(conditions, first.Group.Conditions...)
+	} else {
+		conditions = append(conditions, first)
+	}
+	conditions = append(conditions, others...)
+
+	// Copy, the conditions of first must not change
+	joined := make([]Clause, len(conditions))
+	for i := 0; i < len(conditions); i++ {
+		joined[i] = conditions[i]
+		joined[i].ClauseLogic = logic
+	}
+	return Clause{ClauseLogic: AND, Group: &Where{WhereLogic: logic, Conditions: joined}}
+}
+
+func isJoinedBy(logic ClauseLogic, conditions []Clause) bool {
+	for i := 0; i < len(conditions)-1; i++ {
+		if conditions[i].ClauseLogic != logic {
+			return false
+		}
+	}
+	return true
+}
+
+// Add a condition made with the expression builder, it is
+// ANDed with the other WHERE groups
+// Unlike Where, the values collected by the Make* helpers
+// are left for the clause they were made for
+func (d *DbAdapter) WhereCondition(condition Clause) *DbAdapter {
+	d.whereClauses = append(d.whereClauses, makeConditionGroup(condition))
+	return d
+}
+
+func (d *DbAdapter) HavingCondition(condition Clause) *DbAdapter {
+	d.havingClauses = append(d.havingClauses, makeConditionGroup(condition))
+	return d
+}
+
+// Groups are rendered in parentheses already,
+// a nested group needs no second pair
+func makeConditionGroup(condition Clause) Where {
+	if condition.Group != nil && condition.Group.WhereLogic != NOT {
+		return Where{WhereLogic: AND, Conditions: condition.Group.Conditions}
+	}
+	return Where{WhereLogic: AND, Conditions: []Clause{condition}}
+}
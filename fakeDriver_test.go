@@ -0,0 +1,169 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// A database/sql driver that records the statements it gets
+// and answers every query with the rows set by the test
+type fakeDatabase struct {
+	mu         sync.Mutex
+	statements []fakeStatement
+	columns    []string
+	rows       [][]driver.Value
+}
+
+type fakeStatement struct {
+	query string
+	args  []interface{}
+}
+
+var (
+	fakeDatabases       sync.Map
+	fakeDatabaseCounter int64
+)
+
+func init() {
+	sql.Register("querybuilder_fake", fakeDriver{})
+}
+
+// Returns an adapter for the table users on a new fake database
+func newFakeAdapter(t *testing.T) (*DbAdapter, *fakeDatabase) {
+	t.Helper()
+	dsn := fmt.Sprintf("fake_%d", atomic.AddInt64(&fakeDatabaseCounter, 1))
+	fake := &fakeDatabase{}
+	fakeDatabases.Store(dsn, fake)
+
+	db, err := sql.Open("querybuilder_fake", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeDatabases.Delete(dsn)
+	})
+
+	d := &DbAdapter{}
+	d.InitWithoutConnection(db, TableDetails{Table: "users"})
+	return d, fake
+}
+
+func (f *fakeDatabase) setRows(columns []string, rows ...[]driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.columns = columns
+	f.rows = rows
+}
+
+func (f *fakeDatabase) record(query string, args []driver.NamedValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := []interface{}{}
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	f.statements = append(f.statements, fakeStatement{query: query, args: values})
+}
+
+// The statements executed so far, BEGIN, COMMIT and ROLLBACK included
+func (f *fakeDatabase) queries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	queries := []string{}
+	for _, st := range f.statements {
+		queries = append(queries, st.query)
+	}
+	return queries
+}
+
+func (f *fakeDatabase) lastStatement() fakeStatement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.statements) == 0 {
+		return fakeStatement{}
+	}
+	return f.statements[len(f.statements)-1]
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fake, ok := fakeDatabases.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("Unknown fake database %s.", dsn)
+	}
+	return &fakeConn{db: fake.(*fakeDatabase)}, nil
+}
+
+type fakeConn struct {
+	db *fakeDatabase
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("Prepare is not supported by the fake driver.")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN", nil)
+	return &fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query, args)
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	return &fakeRows{columns: c.db.columns, rows: c.db.rows}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct {
+	db *fakeDatabase
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.db.record("COMMIT", nil)
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
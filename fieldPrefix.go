@@ -0,0 +1,88 @@
+package querybuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prefix unqualified column names with the field prefix of the
+// table, see TableDetails, in the columns of Select, Update and
+// Insert, in conditions, ORDER BY and GROUP BY. Qualified names,
+// names already prefixed, aliases of the select list and Raw
+// expressions are left alone. Default is false.
+// Usage: with the prefix "usr_", SelectByColumns([]string{"name"}) selects usr_name
+func (d *DbAdapter) SetAutoFieldPrefix(enabled bool) {
+	d.connection().setAutoFieldPrefix(enabled)
+}
+
+// Remove the field prefix of the table from the keys of
+// the rows returned by ExecSelect and the like. Default is false.
+func (d *DbAdapter) SetStripFieldPrefix(enabled bool) {
+	d.connection().setStripFieldPrefix(enabled)
+}
+
+func (d *DbAdapter) renderColumn(st *statement, column string) (string, error) {
+	return d.renderIdentifier(st, d.prefixColumn(column))
+}
+
+func (d *DbAdapter) renderColumns(st *statement, columns []string) ([]string, error) {
+	rendered := []string{}
+	for i := 0; i < len(columns); i++ {
+		column, err := d.renderColumn(st, columns[i])
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, column)
+	}
+	return rendered, nil
+}
+
+// The column with the field prefix, if it is a plain name
+// and auto prefixing is enabled, "name AS n" included
+func (d *DbAdapter) prefixColumn(column string) string {
+	prefix := d.dbTableFieldPrefix
+	if prefix == "" || !d.connection().isAutoFieldPrefix() {
+		return column
+	}
+
+	if expression, alias, found := splitAlias(column, false); found {
+		if prefixed := d.prefixColumnName(expression); prefixed != expression {
+			return fmt.Sprintf("%s %s %s", prefixed, As, alias)
+		}
+		return column
+	}
+	return d.prefixColumnName(column)
+}
+
+func (d *DbAdapter) prefixColumnName(column string) string {
+	name := strings.TrimSpace(column)
+	if name == "" || plainNameLength(name) != len(name) || strings.HasPrefix(name, d.dbTableFieldPrefix) || d.isSelectAlias(name) {
+		return column
+	}
+	return d.dbTableFieldPrefix + name
+}
+
+// Aliases of the select list are used as they are,
+// e.g. in ORDER BY or HAVING
+func (d *DbAdapter) isSelectAlias(name string) bool {
+	for i := 0; i < len(d.queryColumns); i++ {
+		if _, alias, found := splitAlias(d.queryColumns[i], false); found && alias == name {
+			return true
+		}
+	}
+	for i := 0; i < len(d.selectSubqueries); i++ {
+		if d.selectSubqueries[i].alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// The key of a column in a result row, see SetStripFieldPrefix
+func (d *DbAdapter) resultColumnName(column string) string {
+	prefix := d.dbTableFieldPrefix
+	if prefix == "" || !d.connection().isStripFieldPrefix() || column == prefix {
+		return column
+	}
+	return strings.TrimPrefix(column, prefix)
+}
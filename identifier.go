@@ -0,0 +1,234 @@
+package querybuilder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Raw expressions are handed around as tokens pointing into
+// the builder that made them, so that a string coming from a
+// request can never be taken for one
+const rawExpressionPrefix = "\x00raw:"
+
+type rawExpression struct {
+	sql  string
+	args []interface{}
+}
+
+// Use sql as it is wherever an identifier is expected: in the
+// select list, as the column of a condition, as a joined table,
+// in ORDER BY and GROUP BY. It can also be compared with in the
+// expression API and be used as a value of Update and Insert. The "?" placeholders
+// of sql take args, which are merged into the arguments of the
+// statement where the expression ends up.
+// The result is only valid for this builder until it is executed.
+// Usage: d.SelectByColumns([]string{"city", d.MakeAsField(d.Raw("COUNT(*)"), "total")})
+//
+//	d.Update([]string{"stock"}, []interface{}{d.Raw("stock - ?", sold)})
+func (d *DbAdapter) Raw(sql string, args ...interface{}) string {
+	d.rawExpressions = append(d.rawExpressions, rawExpression{sql: sql, args: args})
+	return fmt.Sprintf("%s%p:%d\x00", rawExpressionPrefix, d, len(d.rawExpressions)-1)
+}
+
+// A condition made of a Raw expression only
+// Usage: d.WhereCondition(RawCondition(d.Raw("JSON_CONTAINS(tags, ?)", `"go"`)))
+func RawCondition(raw string) Clause {
+	return Clause{ClauseLogic: AND, Column: raw}
+}
+
+func isRawExpression(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, rawExpressionPrefix)
+}
+
+// Identifiers that are not plain table.column names or
+// aliases make the statement fail instead of being used
+// unquoted, Raw is the only way to use expressions then.
+// Default is false.
+func (d *DbAdapter) SetStrictIdentifiers(strict bool) {
+	d.connection().setStrictIdentifiers(strict)
+}
+
+// True if the statement built last, by ToSQL or to execute
+// it, used identifiers that are neither plain names nor Raw
+// as they are, e.g. to log queries that need a Raw
+func (d *DbAdapter) HasPotentialThreat() bool {
+	return d.queryHasPotentialThreat
+}
+
+// Quote a table.column identifier, a Raw expression is used
+// as it is. Anything else fails in strict mode and is used
+// unquoted otherwise.
+func (d *DbAdapter) renderIdentifier(st *statement, identifier string) (string, error) {
+	if rendered, ok, err := d.renderPlainOrRaw(st, identifier); ok || err != nil {
+		return rendered, err
+	}
+	return d.renderUnvalidated(st, identifier)
+}
+
+// Same as renderIdentifier, followed by an optional alias,
+// as in "users.name AS author", or "users u" if implicitAlias
+// is set. Columns need AS, "DISTINCT city" is not an alias.
+func (d *DbAdapter) renderAliasedIdentifier(st *statement, identifier string, implicitAlias bool) (string, error) {
+	if expression, alias, found := splitAlias(identifier, implicitAlias); found {
+		if renderedAlias, ok := quotePlainIdentifier(alias, d.GetDialect(), false); ok {
+			if rendered, ok, err := d.renderPlainOrRaw(st, expression); ok || err != nil {
+				return fmt.Sprintf("%s %s %s", rendered, As, renderedAlias), err
+			}
+		}
+	}
+	return d.renderIdentifier(st, identifier)
+}
+
+// Aliases of tables, subqueries and CTEs are a single name
+func (d *DbAdapter) renderAlias(st *statement, alias string) (string, error) {
+	if rendered, ok := quotePlainIdentifier(strings.TrimSpace(alias), d.GetDialect(), false); ok {
+		return rendered, nil
+	}
+	return d.renderUnvalidated(st, alias)
+}
+
+func (d *DbAdapter) renderIdentifiers(st *statement, identifiers []string) ([]string, error) {
+	rendered := []string{}
+	for i := 0; i < len(identifiers); i++ {
+		identifier, err := d.renderIdentifier(st, identifiers[i])
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, identifier)
+	}
+	return rendered, nil
+}
+
+func (d *DbAdapter) renderPlainOrRaw(st *statement, identifier string) (string, bool, error) {
+	identifier = strings.TrimSpace(identifier)
+	if strings.HasPrefix(identifier, rawExpressionPrefix) {
+		expression, err := d.resolveRawExpression(st, identifier)
+		return expression, err == nil, err
+	}
+	rendered, ok := quotePlainIdentifier(identifier, d.GetDialect(), true)
+	return rendered, ok, nil
+}
+
+func (d *DbAdapter) renderUnvalidated(st *statement, identifier string) (string, error) {
+	if strings.Contains(identifier, rawExpressionPrefix) {
+		return "", fmt.Errorf("Raw expressions can only be used on their own or with an alias.")
+	}
+	if d.connection().isStrictIdentifiers() {
+		return "", fmt.Errorf("%q is not a plain identifier, use Raw for expressions.", identifier)
+	}
+	st.hasPotentialThreat = true
+	return identifier, nil
+}
+
+// The SQL of a Raw expression, its arguments are added to st
+func (d *DbAdapter) resolveRawExpression(st *statement, token string) (string, error) {
+	prefix := fmt.Sprintf("%s%p:", rawExpressionPrefix, d)
+	if !strings.HasPrefix(token, prefix) || !strings.HasSuffix(token, "\x00") {
+		return "", fmt.Errorf("Raw expression was made by another query builder.")
+	}
+	index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(token, prefix), "\x00"))
+	if err != nil || index < 0 || index >= len(d.rawExpressions) {
+		return "", fmt.Errorf("Raw expression is not valid anymore, it was made before the query was executed.")
+	}
+	st.addArgs(d.rawExpressions[index].args...)
+	return d.rawExpressions[index].sql, nil
+}
+
+// Number of arguments of a Raw expression, 0 if the token
+// is not valid, which fails once the statement is built
+func (d *DbAdapter) rawExpressionArgCount(token string) int {
+	st := statement{}
+	if _, err := d.resolveRawExpression(&st, token); err != nil {
+		return 0
+	}
+	return len(st.args)
+}
+
+// Split "expression AS alias", and "expression alias" if implicit is set
+func splitAlias(identifier string, implicit bool) (string, string, bool) {
+	identifier = strings.TrimSpace(identifier)
+	separator := strings.LastIndexAny(identifier, " \t\n")
+	if separator < 0 {
+		return "", "", false
+	}
+	expression := strings.TrimSpace(identifier[:separator])
+	alias := identifier[separator+1:]
+	if len(expression) > 3 && strings.EqualFold(expression[len(expression)-3:], " as") {
+		expression = strings.TrimSpace(expression[:len(expression)-3])
+	} else if !implicit {
+		return "", "", false
+	}
+	return expression, alias, expression != ""
+}
+
+// Quote every part of a plain identifier, parts quoted
+// already with the quote of the dialect are kept. Returns
+// false if identifier is anything else. A path of several
+// parts, optionally ending with *, is only accepted if
+// path is set.
+func quotePlainIdentifier(identifier string, dialect Dialect, path bool) (string, bool) {
+	quote := dialect.QuoteIdentifier("")[:1]
+	parts := []string{}
+	rest := identifier
+	for {
+		var part string
+		switch {
+		case strings.HasPrefix(rest, quote):
+			end := closingQuoteIndex(rest, quote)
+			if end < 0 {
+				return "", false
+			}
+			part, rest = rest[:end], rest[end:]
+		case path && rest == "*":
+			part, rest = rest, ""
+		default:
+			length := plainNameLength(rest)
+			if length == 0 {
+				return "", false
+			}
+			part, rest = dialect.QuoteIdentifier(rest[:length]), rest[length:]
+		}
+		parts = append(parts, part)
+
+		if rest == "" {
+			break
+		}
+		if !path || rest[0] != '.' {
+			return "", false
+		}
+		rest = rest[1:]
+	}
+	return strings.Join(parts, "."), true
+}
+
+// Index after the quote closing the one s starts
+// with, doubled quotes are part of the name
+func closingQuoteIndex(s, quote string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i:i+1] != quote {
+			continue
+		}
+		if i+1 < len(s) && s[i+1:i+2] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return -1
+}
+
+// Length of the unquoted name s starts with
+func plainNameLength(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+		isDigit := c >= '0' && c <= '9'
+		if isLetter || (i != 0 && (isDigit || c == '$')) {
+			continue
+		}
+		return i
+	}
+	return len(s)
+}
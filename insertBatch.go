@@ -0,0 +1,184 @@
+package querybuilder
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BatchOptions limit the size of the statements sent by InsertBatch,
+// zero values fall back to the defaults below
+type BatchOptions struct {
+	// Defaults to the limit of the dialect, 65535 for MySQL
+	MaxPlaceholders int
+	// Estimated size of a single statement, keep it below
+	// max_allowed_packet of the server. Defaults to 4 MB.
+	MaxPacketBytes int
+	// Insert all chunks in one transaction, or none of them
+	Transaction bool
+}
+
+const defaultMaxPacketBytes = 4 << 20
+
+type BatchResult struct {
+	RowsAffected int64
+	// LastInsertId of every chunk, for MySQL that is the id of the
+	// first row of the chunk. 0 if the driver does not report it.
+	FirstInsertIds []int64
+}
+
+func (d *DbAdapter) InsertBatch(columns []string, rows [][]interface{}, options ...BatchOptions) (BatchResult, error) {
+	return d.InsertBatchContext(context.Background(), columns, rows, options...)
+}
+
+// Insert rows with multi-row INSERT ... VALUES(...), (...) statements,
+// split in as many chunks as needed to stay within the options
+// @ param columns []string
+// @ param rows [][]interface{} One slice of values per row, in the order of columns
+// @ param options BatchOptions Optional
+// @ return BatchResult, error
+func (d *DbAdapter) InsertBatchContext(ctx context.Context, columns []string, rows [][]interface{}, options ...BatchOptions) (BatchResult, error) {
+	defer d.unsetQueryParams()
+
+	result := BatchResult{}
+	if d.queryError != nil {
+		return result, d.queryError
+	}
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	batchOptions := BatchOptions{}
+	if len(options) > 0 {
+		batchOptions = options[0]
+	}
+	if batchOptions.MaxPlaceholders <= 0 {
+		batchOptions.MaxPlaceholders = d.GetDialect().MaxPlaceholders()
+	}
+	if batchOptions.MaxPacketBytes <= 0 {
+		batchOptions.MaxPacketBytes = defaultMaxPacketBytes
+	}
+
+	d.setQueryColumns(columns)
+
+	// The values of ON DUPLICATE KEY UPDATE are part of every chunk
+	_, _, upsertArgs, err := d.prepareInsertModifiers(&statement{}, d.dbTable)
+	if err != nil {
+		return result, err
+	}
+	maxRowPlaceholders := batchOptions.MaxPlaceholders - len(upsertArgs)
+
+	statements := []statement{}
+	for _, chunk := range chunkInsertRows(rows, d.rowPlaceholders, maxRowPlaceholders, batchOptions.MaxPacketBytes) {
+		st := statement{}
+		if err := d.prepareInsertRowsStatement(&st, chunk); err != nil {
+			return result, err
+		}
+		st.query = rebindPlaceholders(st.query, d.GetDialect())
+		statements = append(statements, st)
+	}
+
+	runChunks := func(adapter *DbAdapter) error {
+		executor, err := adapter.executor()
+		if err != nil {
+			return err
+		}
+		for _, st := range statements {
+			d.setLastExecutedQuery(st)
+			res, err := executor.ExecContext(ctx, st.query, st.args...)
+			if err != nil {
+				return d.makeQueryError(ctx, st, err)
+			}
+			rowsAffected, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			result.RowsAffected += rowsAffected
+			firstInsertId, err := res.LastInsertId()
+			if err != nil {
+				firstInsertId = 0
+			}
+			result.FirstInsertIds = append(result.FirstInsertIds, firstInsertId)
+		}
+		return nil
+	}
+
+	if !batchOptions.Transaction {
+		return result, runChunks(d)
+	}
+
+	err = d.WithTransactionContext(ctx, nil, func(tx *DbAdapter) error {
+		return runChunks(tx)
+	})
+	if err != nil {
+		// Nothing was inserted after the rollback
+		return BatchResult{}, err
+	}
+	return result, nil
+}
+
+// Split rows so that no chunk exceeds maxPlaceholders or
+// the estimated packet size, every chunk holds at least one row
+func chunkInsertRows(rows [][]interface{}, rowPlaceholders func(row []interface{}) int, maxPlaceholders, maxPacketBytes int) [][][]interface{} {
+	chunks := [][][]interface{}{}
+	chunkStart := 0
+	chunkBytes := 0
+	chunkPlaceholders := 0
+	for i := 0; i < len(rows); i++ {
+		rowBytes := estimateRowBytes(rows[i])
+		placeholders := rowPlaceholders(rows[i])
+		if i > chunkStart && (chunkPlaceholders+placeholders > maxPlaceholders || chunkBytes+rowBytes > maxPacketBytes) {
+			chunks = append(chunks, rows[chunkStart:i])
+			chunkStart = i
+			chunkBytes = 0
+			chunkPlaceholders = 0
+		}
+		chunkBytes += rowBytes
+		chunkPlaceholders += placeholders
+	}
+	return append(chunks, rows[chunkStart:])
+}
+
+// Placeholders of a row in the statement, a Raw
+// value brings one per argument instead of its own
+func (d *DbAdapter) rowPlaceholders(row []interface{}) int {
+	placeholders := 0
+	for _, value := range row {
+		if isRawExpression(value) {
+			placeholders += d.rawExpressionArgCount(value.(string))
+			continue
+		}
+		placeholders++
+	}
+	return placeholders
+}
+
+// Rough size of a row on the wire, including
+// its placeholders and separators
+func estimateRowBytes(row []interface{}) int {
+	size := 4
+	for _, value := range row {
+		size += 3
+		switch v := value.(type) {
+		case nil:
+			size += 4
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		case time.Time:
+			size += 26
+		case bool, int8, uint8:
+			size++
+		case int16, uint16:
+			size += 2
+		case int32, uint32, float32:
+			size += 4
+		case int, uint, int64, uint64, float64:
+			size += 8
+		default:
+			size += len(fmt.Sprint(v))
+		}
+	}
+	return size
+}
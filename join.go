@@ -0,0 +1,68 @@
+package querybuilder
+
+import "fmt"
+
+// Alias the table of the builder, needed to join a table with itself
+// Usage: d.Table("employees").As("e").JoinOnCondition(LeftJoin, "employees", "m", Col("e.manager_id").EqCol("m.id"))
+func (d *DbAdapter) As(alias string) *DbAdapter {
+	d.tableAlias = alias
+	return d
+}
+
+// Join with any conditions, made the same way as for Where,
+// the values are collected the same way too. alias can be empty.
+// The operator and value string of MakeCondition is raw SQL, it
+// is neither quoted nor checked, compare columns with EqCol.
+// Usage: d.JoinOn(LeftJoin, "orders", "o", d.MakeWhereGroup(AND, []Clause{
+//
+//		Col("o.user_id").EqCol("users.id"),
+//		d.MakeCondition(AND, "o.status", d.MakeAggregatedValueWithOperator(Equal, "paid")),
+//	}))
+func (d *DbAdapter) JoinOn(joinType JoinType, table, alias string, on Where) *DbAdapter {
+	on.args = d.takeAggregatedValuesForClauses()
+	d.joins = append(d.joins, join{JoinType: joinType, ForignTable: table, Alias: alias, on: &on})
+	return d
+}
+
+// Same as JoinOn for conditions of the expression API
+// Usage: d.JoinOnCondition(InnerJoin, "orders", "o", Col("o.user_id").EqCol("users.id").And(Col("o.status").Eq("paid")))
+func (d *DbAdapter) JoinOnCondition(joinType JoinType, table, alias string, on Clause) *DbAdapter {
+	group := makeConditionGroup(on)
+	d.joins = append(d.joins, join{JoinType: joinType, ForignTable: table, Alias: alias, on: &group})
+	return d
+}
+
+// Join on columns named the same in both tables
+// Usage: d.JoinUsing(InnerJoin, "profiles", "", "user_id")
+func (d *DbAdapter) JoinUsing(joinType JoinType, table, alias string, columns ...string) *DbAdapter {
+	if len(columns) == 0 {
+		d.setQueryError(fmt.Errorf("Join on %s with USING needs at least one column.", table))
+		return d
+	}
+	d.joins = append(d.joins, join{JoinType: joinType, ForignTable: table, Alias: alias, using: columns})
+	return d
+}
+
+// Every row combined with every row of table
+func (d *DbAdapter) CrossJoin(table, alias string) *DbAdapter {
+	d.joins = append(d.joins, join{JoinType: CrossJoin, ForignTable: table, Alias: alias})
+	return d
+}
+
+// Join a derived table, the alias is required. The
+// condition is left out for CrossJoin
+// Usage: d.JoinSubquery(LeftJoin, totals, "t", Col("t.user_id").EqCol("users.id"))
+func (d *DbAdapter) JoinSubquery(joinType JoinType, subquery Subquery, alias string, on ...Clause) *DbAdapter {
+	if alias == "" {
+		d.setQueryError(fmt.Errorf("Joined subquery needs an alias."))
+		return d
+	}
+
+	joined := join{JoinType: joinType, Alias: alias, subquery: subquery}
+	if len(on) != 0 {
+		group := makeConditionGroup(on[0].And(on[1:]...))
+		joined.on = &group
+	}
+	d.joins = append(d.joins, joined)
+	return d
+}
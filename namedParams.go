@@ -0,0 +1,237 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Run SQL written by hand with :name or @name parameters, taken
+// from a map with string keys or from a struct, mapped the same
+// way as for ExecSelectInto. A name can be used several times,
+// a slice is expanded into a list, e.g. for IN (:ids).
+// MySQL user variables (@var) cannot be used in such a query.
+// Usage: d.QueryNamed("SELECT * FROM users WHERE city = :city AND id IN (:ids)", map[string]interface{}{"city": "Berlin", "ids": ids})
+func (d *DbAdapter) QueryNamed(query string, params interface{}) ([]map[string]interface{}, error) {
+	return d.QueryNamedContext(context.Background(), query, params)
+}
+
+func (d *DbAdapter) QueryNamedContext(ctx context.Context, query string, params interface{}) ([]map[string]interface{}, error) {
+	st, err := d.compileNamed(query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]interface{}
+	err = d.queryStatement(ctx, st, func(rows *sql.Rows) error {
+		var err error
+		result, err = d.scanRows(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (d *DbAdapter) ExecNamed(query string, params interface{}) (sql.Result, error) {
+	return d.ExecNamedContext(context.Background(), query, params)
+}
+
+func (d *DbAdapter) ExecNamedContext(ctx context.Context, query string, params interface{}) (sql.Result, error) {
+	st, err := d.compileNamed(query, params)
+	if err != nil {
+		return nil, err
+	}
+	return d.execStatement(ctx, st)
+}
+
+// Returns the query with the placeholders of the dialect
+// and the arguments in their order, without executing it
+func (d *DbAdapter) BindNamed(query string, params interface{}) (string, []interface{}, error) {
+	st, err := d.compileNamed(query, params)
+	if err != nil {
+		return "", nil, err
+	}
+	return st.query, st.args, nil
+}
+
+// Same as Raw, with named parameters
+// Usage: d.WhereCondition(RawCondition(d.RawNamed("created BETWEEN :from AND :to", period)))
+func (d *DbAdapter) RawNamed(sql string, params interface{}) string {
+	st, err := bindNamed(sql, params, d.GetDialect())
+	if err != nil {
+		d.setQueryError(err)
+	}
+	return d.Raw(st.query, st.args...)
+}
+
+func (d *DbAdapter) compileNamed(query string, params interface{}) (statement, error) {
+	st, err := bindNamed(query, params, d.GetDialect())
+	if err != nil {
+		return st, err
+	}
+	st.query = rebindPlaceholders(st.query, d.GetDialect())
+	return st, nil
+}
+
+// Replace the named parameters of query with "?", quoted
+// text, comments and PostgreSQL casts (::type) are skipped
+func bindNamed(query string, params interface{}, dialect Dialect) (statement, error) {
+	lookup, err := namedParamsLookup(params)
+	if err != nil {
+		return statement{}, err
+	}
+
+	st := statement{}
+	var bound strings.Builder
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingStringQuoteIndex(query[i:], dialect == MySQL)
+			if end < 0 {
+				end = len(query) - i
+			}
+			bound.WriteString(query[i : i+end])
+			i += end - 1
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			bound.WriteString(query[i : i+end])
+			i += end - 1
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i
+			} else {
+				end += 4
+			}
+			bound.WriteString(query[i : i+end])
+			i += end - 1
+		case c == '?':
+			return st, fmt.Errorf("Named queries cannot use ? placeholders.")
+		case (c == ':' || c == '@') && i+1 < len(query) && query[i+1] == c:
+			// ::type and @@variable
+			bound.WriteString(query[i : i+2])
+			i++
+		case (c == ':' || c == '@') && plainNameLength(query[i+1:]) > 0:
+			length := namedParamLength(query[i+1:])
+			name := query[i+1 : i+1+length]
+			value, ok := lookup(name)
+			if !ok {
+				return st, fmt.Errorf("Missing value for the named parameter %s.", name)
+			}
+			placeholders, err := expandNamedParam(&st, name, value)
+			if err != nil {
+				return st, err
+			}
+			bound.WriteString(placeholders)
+			i += length
+		default:
+			bound.WriteByte(c)
+		}
+	}
+	st.query = bound.String()
+	return st, nil
+}
+
+// Same as closingQuoteIndex for the quote s starts with. With
+// backslashEscapes, as in MySQL strings, \' does not close it.
+func closingStringQuoteIndex(s string, backslashEscapes bool) int {
+	if !backslashEscapes || s[0] == '`' {
+		return closingQuoteIndex(s, s[:1])
+	}
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == s[0]:
+			if i+1 < len(s) && s[i+1] == s[0] {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// Names may contain dots for the fields of nested structs
+func namedParamLength(s string) int {
+	length := plainNameLength(s)
+	for length < len(s)-1 && s[length] == '.' {
+		next := plainNameLength(s[length+1:])
+		if next == 0 {
+			break
+		}
+		length += 1 + next
+	}
+	return length
+}
+
+// Slices become one placeholder per element, []byte
+// and driver.Valuer are passed on as they are
+func expandNamedParam(st *statement, name string, value interface{}) (string, error) {
+	if _, ok := value.(driver.Valuer); ok || value == nil {
+		st.addArgs(value)
+		return preparationPlaceHolder, nil
+	}
+
+	list := reflect.ValueOf(value)
+	if (list.Kind() != reflect.Slice && list.Kind() != reflect.Array) || list.Type().Elem().Kind() == reflect.Uint8 {
+		st.addArgs(value)
+		return preparationPlaceHolder, nil
+	}
+
+	if list.Len() == 0 {
+		return "", fmt.Errorf("Named parameter %s is an empty list.", name)
+	}
+	placeholders := []string{}
+	for i := 0; i < list.Len(); i++ {
+		placeholders = append(placeholders, preparationPlaceHolder)
+		st.addArgs(list.Index(i).Interface())
+	}
+	return strings.Join(placeholders, ", "), nil
+}
+
+func namedParamsLookup(params interface{}) (func(name string) (interface{}, bool), error) {
+	value := reflect.ValueOf(params)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("Named parameters need a map with string keys, got %s.", value.Type())
+		}
+		return func(name string) (interface{}, bool) {
+			param := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+			if !param.IsValid() {
+				return nil, false
+			}
+			return param.Interface(), true
+		}, nil
+	case reflect.Struct:
+		mapping := getStructMapping(value.Type())
+		return func(name string) (interface{}, bool) {
+			field, ok := mapping.byColumn[name]
+			if !ok {
+				return nil, false
+			}
+			// A nil pointer to a nested struct binds NULL
+			fieldValue, ok := valueByIndex(value, field.index)
+			if !ok {
+				return nil, true
+			}
+			return fieldValue.Interface(), true
+		}, nil
+	}
+	return nil, fmt.Errorf("Named parameters need a map or a struct, got %T.", params)
+}
@@ -0,0 +1,102 @@
+package querybuilder
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type namedFilter struct {
+	City   string       `db:"city"`
+	MinAge int          `db:"min_age"`
+	Author *typedAuthor `db:"author"`
+}
+
+func TestBindNamed(t *testing.T) {
+	params := map[string]interface{}{
+		"id":    1,
+		"ids":   []int{2, 3},
+		"blob":  []byte("b"),
+		"empty": []int{},
+		"nil":   nil,
+		"note":  sql.NullString{String: "n", Valid: true},
+	}
+	cases := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		params  interface{}
+		bound   string
+		args    []interface{}
+		err     string
+	}{
+		{"repeated names", MySQL, "id = :id OR parent = @id", params, "id = ? OR parent = ?", []interface{}{1, 1}, ""},
+		{"lists are expanded", MySQL, "id IN (:ids) AND data = :blob", params, "id IN (?, ?) AND data = ?", []interface{}{2, 3, []byte("b")}, ""},
+		{"nil and valuers", MySQL, "a = :nil AND b = :note", params, "a = ? AND b = ?", []interface{}{nil, sql.NullString{String: "n", Valid: true}}, ""},
+		{"quotes and comments are skipped", MySQL, "a = ':id' AND `:id` = 1 -- :id\n/* :id */ AND b = :id", params, "a = ':id' AND `:id` = 1 -- :id\n/* :id */ AND b = ?", []interface{}{1}, ""},
+		{"doubled quotes", MySQL, "a = 'it''s :id' AND b = :id", params, "a = 'it''s :id' AND b = ?", []interface{}{1}, ""},
+		{"backslash escapes in MySQL", MySQL, `note = 'it\'s :id' AND b = :id`, params, `note = 'it\'s :id' AND b = ?`, []interface{}{1}, ""},
+		{"escaped backslash in MySQL", MySQL, `path = 'C:\\' AND b = :id`, params, `path = 'C:\\' AND b = ?`, []interface{}{1}, ""},
+		{"no backslash escapes in PostgreSQL", PostgreSQL, `path = 'C:\' AND b = :id`, params, `path = 'C:\' AND b = $1`, []interface{}{1}, ""},
+		{"casts and variables", PostgreSQL, "a = :id::int AND @@version", params, "a = $1::int AND @@version", []interface{}{1}, ""},
+		{"struct fields, nested ones by their column", MySQL, "city = :city AND age >= :min_age AND author = :author.name", namedFilter{City: "c", MinAge: 18, Author: &typedAuthor{Name: "a"}}, "city = ? AND age >= ? AND author = ?", []interface{}{"c", 18, "a"}, ""},
+		{"nil nested struct binds NULL", MySQL, "author = :author.name", &namedFilter{}, "author = ?", []interface{}{nil}, ""},
+		{"missing value", MySQL, "a = :missing", params, "", nil, "Missing value for the named parameter missing"},
+		{"empty list", MySQL, "a IN (:empty)", params, "", nil, "is an empty list"},
+		{"question marks", MySQL, "a = ?", params, "", nil, "cannot use ? placeholders"},
+		{"map keys must be strings", MySQL, "a = :a", map[int]int{}, "", nil, "map with string keys"},
+		{"params must be a map or a struct", MySQL, "a = :a", 1, "", nil, "a map or a struct"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := newTestAdapter(c.dialect)
+			bound, args, err := d.BindNamed(c.query, c.params)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bound != c.bound || !reflect.DeepEqual(args, c.args) {
+				t.Errorf("got %q %v, want %q %v", bound, args, c.bound, c.args)
+			}
+		})
+	}
+}
+
+func TestRawNamed(t *testing.T) {
+	runToSQLCases(t, func() *DbAdapter { return newTestAdapter(PostgreSQL) }, []toSQLCase{
+		{
+			name: "bound in place",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Select().
+					WhereCondition(Col("a").Eq(1).And(RawCondition(d.RawNamed("created BETWEEN :from AND :to", map[string]interface{}{"from": 2, "to": 3})))).
+					OrderBy(OrderBy{Column: "id"})
+			},
+			query: `SELECT * FROM "users" WHERE ("a" = $1 AND created BETWEEN $2 AND $3) ORDER BY "id"`,
+			args:  []interface{}{1, 2, 3},
+		},
+		{
+			name: "errors fail the build",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.Select().WhereCondition(RawCondition(d.RawNamed("a = :missing", map[string]interface{}{})))
+			},
+			err: "Missing value",
+		},
+	})
+}
+
+func TestExecNamed(t *testing.T) {
+	d, fake := newFakeAdapter(t)
+	if _, err := d.ExecNamed("UPDATE users SET city = :city WHERE id IN (:ids)", map[string]interface{}{"city": "c", "ids": []int64{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	st := fake.lastStatement()
+	if st.query != "UPDATE users SET city = ? WHERE id IN (?, ?)" || !reflect.DeepEqual(st.args, []interface{}{"c", int64(1), int64(2)}) {
+		t.Errorf("unexpected statement %q %v", st.query, st.args)
+	}
+}
@@ -1,20 +1,34 @@
 package querybuilder
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"fmt"
 )
 
-func (d *DbAdapter) Insert(columns []string, values []interface{}) sql.Result {
+func (d *DbAdapter) Insert(columns []string, values []interface{}) (sql.Result, error) {
+	return d.InsertContext(context.Background(), columns, values)
+}
+
+func (d *DbAdapter) InsertContext(ctx context.Context, columns []string, values []interface{}) (sql.Result, error) {
+	return d.InsertValues(columns, values).ExecInsertContext(ctx)
+}
+
+// Same as Insert, but the statement is only built
+// and executed with ExecInsert, or returned by ToSQL
+func (d *DbAdapter) InsertValues(columns []string, values []interface{}) *DbAdapter {
+	d.queryType = queryTypeInsert
 	d.setQueryColumns(columns)
-	valuesPlaceHolder := []string{}
-	for i := 0; i < len(columns); i++ {
-		valuesPlaceHolder = append(valuesPlaceHolder, preparationPlaceHolder)
-		d.setAggregatedValueForPreparedStatement(values[i])
-	}
-	d.prepareInsertStatement(valuesPlaceHolder)
+	d.queryValues = values
+	return d
+}
+
+func (d *DbAdapter) ExecInsert() (sql.Result, error) {
+	return d.ExecInsertContext(context.Background())
+}
 
-	return d.runExec()
+func (d *DbAdapter) ExecInsertContext(ctx context.Context) (sql.Result, error) {
+	return d.runExec(ctx)
 }
 
 func (d *DbAdapter) Delete() *DbAdapter {
@@ -39,18 +53,26 @@ func (d *DbAdapter) SelectRow() *DbAdapter {
 	return d
 }
 
+// The values collected by the Make* helpers so far belong
+// to the columns, e.g. for MATCH(...) AGAINST(?) AS score.
+// They cannot be combined with Raw columns that take arguments.
 func (d *DbAdapter) SelectByColumns(columns []string) *DbAdapter {
 	d.queryType = queryTypeSelect
-	d.setQueryColumns(columns)
+	d.setSelectColumns(columns)
 	return d
 }
 
 func (d *DbAdapter) SelectRowByColumns(columns []string) *DbAdapter {
 	d.queryType = queryTypeSelectRow
-	d.setQueryColumns(columns)
+	d.setSelectColumns(columns)
 	return d
 }
 
+func (d *DbAdapter) setSelectColumns(columns []string) {
+	d.setQueryColumns(columns)
+	d.queryColumnArgs = append(d.queryColumnArgs, d.takeAggregatedValuesForClauses()...)
+}
+
 func (d *DbAdapter) setQueryColumns(columns []string) {
 	if columns != nil { // skip the len validation here, as it is taken care in the clauseBuilder
 		for i := 0; i < len(columns); i++ {
@@ -59,116 +81,194 @@ func (d *DbAdapter) setQueryColumns(columns []string) {
 	}
 }
 
-func (d *DbAdapter) ExecSelect() []map[string]interface{} {
-	d.makeQueryStatement()
+func (d *DbAdapter) ExecSelect() ([]map[string]interface{}, error) {
+	return d.ExecSelectContext(context.Background())
+}
+
+func (d *DbAdapter) ExecSelectContext(ctx context.Context) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	err := d.runQuery(ctx, func(rows *sql.Rows) error {
+		var err error
+		result, err = d.scanRows(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	d.prepareClauseValuesForPreparedStatement()
+// Execute the select statement and hand the rows
+// to scan, errors of scan are wrapped like query errors
+func (d *DbAdapter) runQuery(ctx context.Context, scan func(rows *sql.Rows) error) error {
+	defer d.unsetQueryParams()
+
+	st, err := d.compileStatement()
+	if err != nil {
+		return err
+	}
+	return d.queryStatement(ctx, st, scan)
+}
+
+// Run a compiled statement, see runQuery
+func (d *DbAdapter) queryStatement(ctx context.Context, st statement, scan func(rows *sql.Rows) error) error {
 
 	// Set query before execution
-	d.setLastExecutedQuery()
+	d.setLastExecutedQuery(st)
 
-	rows, err := d._db.Query(d.queryString, d.queryAggregatedValuesPreparedStatement...)
+	executor, err := d.executor()
+	if err != nil {
+		return err
+	}
 
+	rows, err := executor.QueryContext(ctx, st.query, st.args...)
+	if err != nil {
+		return d.makeQueryError(ctx, st, err)
+	}
 	defer rows.Close()
-	d.unsetQueryParams()
 
-	if err != nil {
-		d.handleQueryResultError(err)
-		return nil
+	if err = scan(rows); err != nil {
+		return d.makeQueryError(ctx, st, err)
 	}
+	return nil
 
-	return d.scanRows(rows)
+}
 
+// Returns ErrNoRows if nothing matched and
+// ErrMultipleRows if the query was ambiguous
+func (d *DbAdapter) ExecSelectRow() (map[string]interface{}, error) {
+	return d.ExecSelectRowContext(context.Background())
 }
 
-func (d *DbAdapter) ExecSelectRow() map[string]interface{} {
-	result := d.ExecSelect()
-	if result != nil && len(result) == 1 {
-		return result[0]
+func (d *DbAdapter) ExecSelectRowContext(ctx context.Context) (map[string]interface{}, error) {
+	result, err := d.ExecSelectContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	switch len(result) {
+	case 0:
+		return nil, ErrNoRows
+	case 1:
+		return result[0], nil
+	}
+	return nil, ErrMultipleRows
 }
 
-func (d *DbAdapter) ExecUpdate() int64 {
-	return d.execUpdateOrDelete()
+func (d *DbAdapter) ExecUpdate() (int64, error) {
+	return d.execUpdateOrDelete(context.Background())
 }
 
-func (d *DbAdapter) ExecDelete() int64 {
-	return d.execUpdateOrDelete()
+func (d *DbAdapter) ExecUpdateContext(ctx context.Context) (int64, error) {
+	return d.execUpdateOrDelete(ctx)
 }
 
-func (d *DbAdapter) execUpdateOrDelete() int64 {
-	d.makeQueryStatement()
-	return d.rowsAffected(d.runExec())
+func (d *DbAdapter) ExecDelete() (int64, error) {
+	return d.execUpdateOrDelete(context.Background())
 }
 
-func (d *DbAdapter) makeQueryStatement() {
+func (d *DbAdapter) ExecDeleteContext(ctx context.Context) (int64, error) {
+	return d.execUpdateOrDelete(ctx)
+}
 
-	d.prepareQueryStatement()
-	// Skip Join on Update and Delete
-	// Join is exceptionally taken care in Update,
-	// but skipped completely for Delete
-	if d.queryType != queryTypeUpdate && d.queryType != queryTypeDelete {
-		d.initBuildJoin()
+func (d *DbAdapter) execUpdateOrDelete(ctx context.Context) (int64, error) {
+	result, err := d.runExec(ctx)
+	if err != nil {
+		return 0, err
 	}
-	d.initBuildWhereClauses()
-	d.initBuildOrderBy()
-	d.initBuildGroupBy()
-	d.initBuildLimit()
+	return d.rowsAffected(result)
+}
 
+// Returns the statement and its arguments without executing
+// it, the builder is left untouched and can still be executed.
+// Usage: query, args, err := d.Select().Where(...).ToSQL()
+func (d *DbAdapter) ToSQL() (string, []interface{}, error) {
+	st, err := d.compileStatement()
+	if err != nil {
+		return "", nil, err
+	}
+	return st.query, st.args, nil
 }
 
-func (d *DbAdapter) runExec() sql.Result {
+func (d *DbAdapter) runExec(ctx context.Context) (sql.Result, error) {
 
-	d.prepareClauseValuesForPreparedStatement()
+	defer d.unsetQueryParams()
 
-	// Set query before execution
-	d.setLastExecutedQuery()
+	// Errors collected while building the statement
+	// are reported before anything hits the database
+	st, err := d.compileStatement()
+	if err != nil {
+		return nil, err
+	}
+	return d.execStatement(ctx, st)
+}
 
-	res, err := d._db.Exec(d.queryString, d.queryAggregatedValuesPreparedStatement...)
+// Execute a compiled statement, see runExec
+func (d *DbAdapter) execStatement(ctx context.Context, st statement) (sql.Result, error) {
 
-	defer d.unsetQueryParams()
+	// Set query before execution
+	d.setLastExecutedQuery(st)
 
-	// Handle Query execution error here on global level
+	executor, err := d.executor()
 	if err != nil {
-		d.handleQueryResultError(err)
-		return nil
+		return nil, err
 	}
-	return res
+
+	res, err := executor.ExecContext(ctx, st.query, st.args...)
+	if err != nil {
+		return nil, d.makeQueryError(ctx, st, err)
+	}
+	return res, nil
 
 }
 
-func (d *DbAdapter) rowsAffected(result sql.Result) int64 {
+func (d *DbAdapter) rowsAffected(result sql.Result) (int64, error) {
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		d.handleQueryResultError(err)
-		return 0
+		return 0, err
 	}
-	return rowsAffected
+	return rowsAffected, nil
 }
 
-func (d *DbAdapter) LastInsertedId(result sql.Result) int64 {
+func (d *DbAdapter) LastInsertedId(result sql.Result) (int64, error) {
+	if result == nil {
+		return 0, fmt.Errorf("No result to read the last inserted id from.")
+	}
 	lastInsertedId, err := result.LastInsertId()
 	if err != nil {
-		d.handleQueryResultError(err)
-		return 0
+		return 0, err
 	}
-	return lastInsertedId
+	return lastInsertedId, nil
 }
 
-// Handle safely ignorable query execution errors
-func (d *DbAdapter) handleQueryResultError(err error) {
-	d.PrintLastExecutedQuery()
-	log.Println(err)
+// Record the first error found while building the
+// statement, it is returned once the query is executed
+func (d *DbAdapter) setQueryError(err error) {
+	if d.queryError == nil {
+		d.queryError = err
+	}
+}
+
+// Wrap errors returned by the database with the
+// statement that was sent, see QueryError. If the
+// context is done its error takes precedence, drivers
+// tend to report a cancelled query as a broken connection
+func (d *DbAdapter) makeQueryError(ctx context.Context, st statement, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = &ContextError{Err: ctxErr, DriverErr: err}
+	}
+	return &QueryError{Query: st.query, Args: st.args, Err: err}
 }
 
-func (d *DbAdapter) scanRows(rows *sql.Rows) []map[string]interface{} {
+func (d *DbAdapter) scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
 
 	// Get column types and count
 	columns, err := rows.Columns()
 	if err != nil {
-		d.PrintLastExecutedQuery()
-		log.Fatalf("Failed to get columns: %v", err)
+		return nil, fmt.Errorf("Failed to get columns: %w", err)
+	}
+
+	for i := 0; i < len(columns); i++ {
+		columns[i] = d.resultColumnName(columns[i])
 	}
 
 	columnCount := len(columns)
@@ -186,8 +286,7 @@ func (d *DbAdapter) scanRows(rows *sql.Rows) []map[string]interface{} {
 	for rows.Next() {
 		// Scan into the value pointers
 		if err := rows.Scan(valuePtrs...); err != nil {
-			d.PrintLastExecutedQuery()
-			log.Fatalf("Failed to scan row: %v", err)
+			return nil, fmt.Errorf("Failed to scan row: %w", err)
 		}
 
 		// Process each value
@@ -207,9 +306,8 @@ func (d *DbAdapter) scanRows(rows *sql.Rows) []map[string]interface{} {
 	}
 
 	if err = rows.Err(); err != nil {
-		d.PrintLastExecutedQuery()
-		log.Fatalf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
 	}
 
-	return dataToReturn
+	return dataToReturn, nil
 }
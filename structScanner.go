@@ -0,0 +1,380 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Columns are mapped to struct fields by the db tag,
+// fields without tag by their name in snake_case.
+//
+//	type User struct {
+//		ID        int64          `db:"id"`
+//		Name      sql.NullString // name
+//		CreatedAt time.Time      // created_at
+//		Audit                    // embedded, its fields are mapped as if declared on User
+//		Author    Author         `db:"author"` // maps the columns aliased author.id, author.name...
+//		Internal  string         `db:"-"`      // never mapped
+//	}
+
+type fieldMapping struct {
+	index   []int
+	column  string
+	options []string
+}
+
+func (f *fieldMapping) hasOption(option string) bool {
+	for i := 0; i < len(f.options); i++ {
+		if f.options[i] == option {
+			return true
+		}
+	}
+	return false
+}
+
+type structMapping struct {
+	// In declaration order
+	fields   []*fieldMapping
+	byColumn map[string]*fieldMapping
+}
+
+var structMappings sync.Map
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+func getStructMapping(structType reflect.Type) *structMapping {
+	if mapping, ok := structMappings.Load(structType); ok {
+		return mapping.(*structMapping)
+	}
+	mapping := &structMapping{byColumn: map[string]*fieldMapping{}}
+	mapping.addFields(structType, nil, "", []reflect.Type{structType})
+	mapping.resolveColumns()
+	structMappings.Store(structType, mapping)
+	return mapping
+}
+
+// Add every mapped field of structType, path holds the structs
+// from the outer one down to structType
+func (m *structMapping) addFields(structType reflect.Type, index []int, columnPrefix string, path []reflect.Type) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tagParts := strings.Split(field.Tag.Get("db"), ",")
+		name := tagParts[0]
+		if name == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if isNestedStruct(fieldType) {
+			// A struct nested in itself, e.g. Parent *Category,
+			// would never end and is not mapped
+			if containsType(path, fieldType) {
+				continue
+			}
+			nestedPath := append(append([]reflect.Type{}, path...), fieldType)
+			if field.Anonymous && name == "" {
+				m.addFields(fieldType, fieldIndex, columnPrefix, nestedPath)
+				continue
+			}
+			if name == "" {
+				name = snakeCase(field.Name)
+			}
+			m.addFields(fieldType, fieldIndex, fmt.Sprintf("%s%s.", columnPrefix, name), nestedPath)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = snakeCase(field.Name)
+		}
+
+		m.fields = append(m.fields, &fieldMapping{index: fieldIndex, column: columnPrefix + name, options: tagParts[1:]})
+	}
+}
+
+// Keep one field per column. As with Go's own field promotion the
+// field closest to the outer struct wins and fields of the same
+// depth hide each other. The winner takes the place of the first
+// field of its column.
+func (m *structMapping) resolveColumns() {
+	ambiguous := map[string]bool{}
+	for _, field := range m.fields {
+		existing, ok := m.byColumn[field.column]
+		switch {
+		case !ok || len(field.index) < len(existing.index):
+			m.byColumn[field.column] = field
+			ambiguous[field.column] = false
+		case len(field.index) == len(existing.index):
+			ambiguous[field.column] = true
+		}
+	}
+
+	fields := []*fieldMapping{}
+	placed := map[string]bool{}
+	for _, field := range m.fields {
+		if placed[field.column] {
+			continue
+		}
+		placed[field.column] = true
+		if ambiguous[field.column] {
+			delete(m.byColumn, field.column)
+			continue
+		}
+		fields = append(fields, m.byColumn[field.column])
+	}
+	m.fields = fields
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for i := 0; i < len(types); i++ {
+		if types[i] == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Structs other than time.Time and sql.Scanner
+// implementations hold more than one column
+func isNestedStruct(fieldType reflect.Type) bool {
+	if fieldType.Kind() != reflect.Struct || fieldType == timeType {
+		return false
+	}
+	return !reflect.PtrTo(fieldType).Implements(scannerType)
+}
+
+// UserID -> user_id, HTTPStatus -> http_status
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var snake strings.Builder
+	for i := 0; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) {
+			previousIsLower := i > 0 && !unicode.IsUpper(runes[i-1]) && runes[i-1] != '_'
+			nextIsLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if previousIsLower || nextIsLower {
+				snake.WriteRune('_')
+			}
+			snake.WriteRune(unicode.ToLower(runes[i]))
+			continue
+		}
+		snake.WriteRune(runes[i])
+	}
+	return snake.String()
+}
+
+// Columns of the result that have no matching field make the
+// scan fail instead of being skipped, default is false
+func (d *DbAdapter) SetStrictScan(strict bool) {
+	d.connection().setStrictScan(strict)
+}
+
+func (d *DbAdapter) ExecSelectInto(dest interface{}) error {
+	return d.ExecSelectIntoContext(context.Background(), dest)
+}
+
+// Usage: var users []User; d.Select().Where(...).ExecSelectInto(&users)
+// @ param dest Pointer to a slice of structs or of pointers to structs
+// @ return error
+func (d *DbAdapter) ExecSelectIntoContext(ctx context.Context, dest interface{}) error {
+	sliceValue := reflect.ValueOf(dest)
+	if sliceValue.Kind() != reflect.Ptr || sliceValue.IsNil() || sliceValue.Elem().Kind() != reflect.Slice {
+		d.unsetQueryParams()
+		return fmt.Errorf("Destination must be a pointer to a slice, got %T.", dest)
+	}
+	sliceValue = sliceValue.Elem()
+
+	elementType := sliceValue.Type().Elem()
+	isPointer := elementType.Kind() == reflect.Ptr
+	structType := elementType
+	if isPointer {
+		structType = elementType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		d.unsetQueryParams()
+		return fmt.Errorf("Destination must be a slice of structs, got %T.", dest)
+	}
+
+	return d.runQuery(ctx, func(rows *sql.Rows) error {
+		sliceValue.Set(reflect.MakeSlice(sliceValue.Type(), 0, 0))
+		return d.scanStructRows(rows, structType, func(row reflect.Value) {
+			if isPointer {
+				sliceValue.Set(reflect.Append(sliceValue, row))
+			} else {
+				sliceValue.Set(reflect.Append(sliceValue, row.Elem()))
+			}
+		})
+	})
+}
+
+func (d *DbAdapter) ExecSelectRowInto(dest interface{}) error {
+	return d.ExecSelectRowIntoContext(context.Background(), dest)
+}
+
+// Returns ErrNoRows if nothing matched and
+// ErrMultipleRows if the query was ambiguous
+// @ param dest Pointer to a struct
+// @ return error
+func (d *DbAdapter) ExecSelectRowIntoContext(ctx context.Context, dest interface{}) error {
+	structValue := reflect.ValueOf(dest)
+	if structValue.Kind() != reflect.Ptr || structValue.IsNil() || structValue.Elem().Kind() != reflect.Struct {
+		d.unsetQueryParams()
+		return fmt.Errorf("Destination must be a pointer to a struct, got %T.", dest)
+	}
+
+	found := 0
+	err := d.runQuery(ctx, func(rows *sql.Rows) error {
+		return d.scanStructRows(rows, structValue.Elem().Type(), func(row reflect.Value) {
+			if found == 0 {
+				structValue.Elem().Set(row.Elem())
+			}
+			found++
+		})
+	})
+	if err != nil {
+		return err
+	}
+	switch found {
+	case 0:
+		return ErrNoRows
+	case 1:
+		return nil
+	}
+	return ErrMultipleRows
+}
+
+// Scan every row into a new struct of structType and hand a
+// pointer to it to collect
+func (d *DbAdapter) scanStructRows(rows *sql.Rows, structType reflect.Type, collect func(row reflect.Value)) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("Failed to get columns: %w", err)
+	}
+
+	mapping := getStructMapping(structType)
+	strict := d.connection().isStrictScan()
+	fields := make([]*fieldMapping, len(columns))
+	prefix := d.dbTableFieldPrefix
+	for i, column := range columns {
+		fields[i] = mapping.byColumn[column]
+		// Fields are usually named without the field prefix
+		if fields[i] == nil && prefix != "" && strings.HasPrefix(column, prefix) {
+			fields[i] = mapping.byColumn[strings.TrimPrefix(column, prefix)]
+		}
+		if fields[i] == nil && strict {
+			return fmt.Errorf("Column %s has no matching field in %s.", column, structType)
+		}
+	}
+
+	valuePtrs := make([]interface{}, len(columns))
+	for rows.Next() {
+		row := reflect.New(structType)
+		for i := 0; i < len(columns); i++ {
+			if fields[i] == nil {
+				valuePtrs[i] = new(interface{})
+				continue
+			}
+			valuePtrs[i] = fieldScanTarget(fieldByIndex(row.Elem(), fields[i].index))
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return fmt.Errorf("Failed to scan row: %w", err)
+		}
+		collect(row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("Error iterating rows: %w", err)
+	}
+	return nil
+}
+
+// Like reflect.Value.FieldByIndex, but allocates
+// nil pointers to embedded and nested structs
+func fieldByIndex(value reflect.Value, index []int) reflect.Value {
+	for i, fieldIndex := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value
+}
+
+// database/sql converts into most field types on its own,
+// times need help when the driver returns them as text
+func fieldScanTarget(field reflect.Value) interface{} {
+	if field.Type() == timeType || (field.Kind() == reflect.Ptr && field.Type().Elem() == timeType) {
+		return &timeScanner{field: field}
+	}
+	return field.Addr().Interface()
+}
+
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02",
+	"15:04:05",
+}
+
+type timeScanner struct {
+	field reflect.Value
+}
+
+func (s *timeScanner) Scan(src interface{}) error {
+	var parsed time.Time
+	switch value := src.(type) {
+	case nil:
+		s.field.Set(reflect.Zero(s.field.Type()))
+		return nil
+	case time.Time:
+		parsed = value
+	case []byte:
+		return s.Scan(string(value))
+	case string:
+		if strings.HasPrefix(value, "0000-00-00") {
+			break
+		}
+		var err error
+		for _, layout := range timeLayouts {
+			if parsed, err = time.Parse(layout, value); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("Cannot parse %q as time.", value)
+		}
+	default:
+		return fmt.Errorf("Cannot scan %T into time.Time.", src)
+	}
+
+	if s.field.Kind() == reflect.Ptr {
+		s.field.Set(reflect.ValueOf(&parsed))
+	} else {
+		s.field.Set(reflect.ValueOf(parsed))
+	}
+	return nil
+}
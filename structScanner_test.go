@@ -0,0 +1,157 @@
+package querybuilder
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type scanAudit struct {
+	CreatedAt time.Time
+	UpdatedBy string `db:"updated_by"`
+}
+
+type scanAuthor struct {
+	ID   int64 `db:"id"`
+	Name string
+}
+
+type scanUser struct {
+	ID       int64 `db:"id"`
+	FullName sql.NullString
+	scanAudit
+	Author   *scanAuthor `db:"author"`
+	Internal string      `db:"-"`
+	secret   string
+}
+
+type scanCategory struct {
+	ID     int64         `db:"id"`
+	Name   string        `db:"name"`
+	Parent *scanCategory `db:"parent"`
+	Tree   scanTree
+}
+
+type scanTree struct {
+	Root *scanCategory
+}
+
+type scanBase struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+type scanShadow struct {
+	scanBase
+	ID int64 `db:"id"`
+}
+
+type scanOther struct {
+	Name string `db:"name"`
+}
+
+type scanAmbiguous struct {
+	scanBase
+	scanOther
+}
+
+func mappedColumns(structType reflect.Type) []string {
+	columns := []string{}
+	for _, field := range getStructMapping(structType).fields {
+		columns = append(columns, field.column)
+	}
+	return columns
+}
+
+func TestStructMapping(t *testing.T) {
+	cases := []struct {
+		name    string
+		value   interface{}
+		columns []string
+	}{
+		{"tags, names, embedded and nested structs", scanUser{}, []string{"id", "full_name", "created_at", "updated_by", "author.id", "author.name"}},
+		{"structs nested in themselves are not mapped", scanCategory{}, []string{"id", "name"}},
+		{"outer fields hide embedded ones", scanShadow{}, []string{"id", "name"}},
+		{"fields of the same depth hide each other", scanAmbiguous{}, []string{"id"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := mappedColumns(reflect.TypeOf(c.value)); !reflect.DeepEqual(got, c.columns) {
+				t.Errorf("got %v, want %v", got, c.columns)
+			}
+		})
+	}
+
+	shadow := getStructMapping(reflect.TypeOf(scanShadow{}))
+	if index := shadow.byColumn["id"].index; !reflect.DeepEqual(index, []int{1}) {
+		t.Errorf("expected id to map to the outer field, got index %v", index)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"ID":         "id",
+		"UserID":     "user_id",
+		"HTTPStatus": "http_status",
+		"CreatedAt":  "created_at",
+		"already_ok": "already_ok",
+	}
+	for name, want := range cases {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestExecSelectInto(t *testing.T) {
+	d, fake := newFakeAdapter(t)
+	fake.setRows([]string{"id", "full_name", "created_at", "author.name", "unknown"},
+		[]driver.Value{int64(1), "Ada", []byte("2024-05-01 10:00:00"), "Bob", int64(0)},
+		[]driver.Value{int64(2), nil, nil, "", int64(0)},
+	)
+
+	var users []scanUser
+	if err := d.Select().ExecSelectInto(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+	if users[0].ID != 1 || users[0].FullName.String != "Ada" || users[0].Author == nil || users[0].Author.Name != "Bob" {
+		t.Errorf("unexpected first user %+v", users[0])
+	}
+	if want := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC); !users[0].CreatedAt.Equal(want) {
+		t.Errorf("got created_at %v, want %v", users[0].CreatedAt, want)
+	}
+	if users[1].FullName.Valid || !users[1].CreatedAt.IsZero() {
+		t.Errorf("expected NULL columns to give zero values, got %+v", users[1])
+	}
+
+	// The same rows fail in strict mode because of the unknown column
+	d.SetStrictScan(true)
+	if err := d.Select().ExecSelectInto(&users); err == nil {
+		t.Errorf("expected the unknown column to fail in strict mode")
+	}
+}
+
+func TestExecSelectRowInto(t *testing.T) {
+	d, fake := newFakeAdapter(t)
+	var user scanUser
+
+	fake.setRows([]string{"id"})
+	if err := d.SelectRow().ExecSelectRowInto(&user); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows, got %v", err)
+	}
+
+	fake.setRows([]string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(2)})
+	if err := d.SelectRow().ExecSelectRowInto(&user); !errors.Is(err, ErrMultipleRows) {
+		t.Errorf("expected ErrMultipleRows, got %v", err)
+	}
+
+	if err := d.SelectRow().ExecSelectRowInto(user); err == nil {
+		t.Errorf("expected a non-pointer destination to fail")
+	}
+}
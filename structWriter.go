@@ -0,0 +1,153 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Fields are mapped to columns as in structScanner.go, two tag
+// options control which of them are written:
+//
+//	ID      int64  `db:"id,autoincrement"` // never inserted or updated
+//	Comment string `db:"comment,omitempty"` // skipped if it holds its zero value
+//
+// Fields of nested structs are never written.
+
+func (d *DbAdapter) InsertStruct(v interface{}) (sql.Result, error) {
+	return d.InsertStructContext(context.Background(), v)
+}
+
+func (d *DbAdapter) InsertStructContext(ctx context.Context, v interface{}) (sql.Result, error) {
+	columns, values, err := structColumnValues(v, nil)
+	if err != nil {
+		d.unsetQueryParams()
+		return nil, err
+	}
+	return d.InsertContext(ctx, columns, values)
+}
+
+func (d *DbAdapter) InsertMap(columnValues map[string]interface{}) (sql.Result, error) {
+	return d.InsertMapContext(context.Background(), columnValues)
+}
+
+// Columns are inserted in alphabetical order
+func (d *DbAdapter) InsertMapContext(ctx context.Context, columnValues map[string]interface{}) (sql.Result, error) {
+	columns, values := mapColumnValues(columnValues)
+	return d.InsertContext(ctx, columns, values)
+}
+
+// Same as Update, with the columns and values taken from v.
+// If onlyFields is given, only those fields are updated, by
+// field or column name, omitempty is ignored for them.
+// Usage: d.UpdateStruct(user, "name", "email").Where(...).ExecUpdate()
+func (d *DbAdapter) UpdateStruct(v interface{}, onlyFields ...string) *DbAdapter {
+	columns, values, err := structColumnValues(v, onlyFields)
+	if err != nil {
+		d.setQueryError(err)
+	}
+	return d.Update(columns, values)
+}
+
+// Columns are updated in alphabetical order
+func (d *DbAdapter) UpdateMap(columnValues map[string]interface{}) *DbAdapter {
+	columns, values := mapColumnValues(columnValues)
+	return d.Update(columns, values)
+}
+
+func structColumnValues(v interface{}, onlyFields []string) ([]string, []interface{}, error) {
+	structValue := reflect.ValueOf(v)
+	for structValue.Kind() == reflect.Ptr {
+		if structValue.IsNil() {
+			return nil, nil, fmt.Errorf("Cannot take the values of a nil %T.", v)
+		}
+		structValue = structValue.Elem()
+	}
+	if structValue.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("Expected a struct, got %T.", v)
+	}
+
+	structType := structValue.Type()
+	fields := getStructMapping(structType).fields
+	onlyColumns := map[string]bool{}
+	for _, onlyField := range onlyFields {
+		column, ok := findStructColumn(structType, fields, onlyField)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s has no field %s.", structType, onlyField)
+		}
+		onlyColumns[column] = true
+	}
+
+	columns := []string{}
+	values := []interface{}{}
+	for _, field := range fields {
+		if strings.Contains(field.column, ".") || field.hasOption("autoincrement") {
+			continue
+		}
+		if len(onlyColumns) > 0 && !onlyColumns[field.column] {
+			continue
+		}
+
+		fieldValue, ok := valueByIndex(structValue, field.index)
+		if !ok {
+			// Field of a nil embedded struct
+			continue
+		}
+		if len(onlyColumns) == 0 && field.hasOption("omitempty") && fieldValue.IsZero() {
+			continue
+		}
+
+		columns = append(columns, field.column)
+		values = append(values, fieldValue.Interface())
+	}
+	return columns, values, nil
+}
+
+// Match a field by its column or its Go name
+func findStructColumn(structType reflect.Type, fields []*fieldMapping, name string) (string, bool) {
+	for _, field := range fields {
+		if field.column == name {
+			return field.column, true
+		}
+	}
+	if field, ok := structType.FieldByName(name); ok {
+		for _, mapped := range fields {
+			if reflect.DeepEqual(mapped.index, field.Index) {
+				return mapped.column, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Like reflect.Value.FieldByIndex, but reports nil embedded structs
+// instead of panicking
+func valueByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+	for i, fieldIndex := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value, true
+}
+
+func mapColumnValues(columnValues map[string]interface{}) ([]string, []interface{}) {
+	columns := make([]string, 0, len(columnValues))
+	for column := range columnValues {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	values := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		values = append(values, columnValues[column])
+	}
+	return columns, values
+}
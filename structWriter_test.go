@@ -0,0 +1,131 @@
+package querybuilder
+
+import (
+	"reflect"
+	"testing"
+)
+
+type writerAudit struct {
+	UpdatedBy string `db:"updated_by"`
+}
+
+type writerUser struct {
+	ID      int64  `db:"id,autoincrement"`
+	Name    string `db:"name"`
+	Comment string `db:"comment,omitempty"`
+	*writerAudit
+	Author typedAuthor `db:"author"`
+}
+
+func TestStructColumnValues(t *testing.T) {
+	cases := []struct {
+		name       string
+		value      interface{}
+		onlyFields []string
+		columns    []string
+		values     []interface{}
+		err        bool
+	}{
+		{
+			name:    "autoincrement, omitempty, nil embedded and nested structs are skipped",
+			value:   writerUser{ID: 1, Name: "a"},
+			columns: []string{"name"},
+			values:  []interface{}{"a"},
+		},
+		{
+			name:    "embedded and set omitempty fields are written",
+			value:   &writerUser{Name: "a", Comment: "c", writerAudit: &writerAudit{UpdatedBy: "b"}},
+			columns: []string{"name", "comment", "updated_by"},
+			values:  []interface{}{"a", "c", "b"},
+		},
+		{
+			name:       "only the given fields, by field or column name",
+			value:      writerUser{Name: "a"},
+			onlyFields: []string{"Comment", "name"},
+			columns:    []string{"name", "comment"},
+			values:     []interface{}{"a", ""},
+		},
+		{
+			name:    "outer fields hide embedded ones",
+			value:   scanShadow{scanBase: scanBase{ID: 1, Name: "n"}, ID: 2},
+			columns: []string{"id", "name"},
+			values:  []interface{}{int64(2), "n"},
+		},
+		{name: "unknown field", value: writerUser{}, onlyFields: []string{"Missing"}, err: true},
+		{name: "nil pointer", value: (*writerUser)(nil), err: true},
+		{name: "not a struct", value: 1, err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			columns, values, err := structColumnValues(c.value, c.onlyFields)
+			if c.err {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(columns, c.columns) || !reflect.DeepEqual(values, c.values) {
+				t.Errorf("got %v %v, want %v %v", columns, values, c.columns, c.values)
+			}
+		})
+	}
+}
+
+func TestInsertStructAndMap(t *testing.T) {
+	d, fake := newFakeAdapter(t)
+
+	if _, err := d.InsertStruct(scanShadow{scanBase: scanBase{Name: "n"}, ID: 2}); err != nil {
+		t.Fatal(err)
+	}
+	st := fake.lastStatement()
+	if st.query != "INSERT INTO `users` (`id`, `name`) VALUES(?, ?)" || !reflect.DeepEqual(st.args, []interface{}{int64(2), "n"}) {
+		t.Errorf("unexpected statement %q %v", st.query, st.args)
+	}
+
+	if _, err := d.InsertMap(map[string]interface{}{"name": "a", "age": 3}); err != nil {
+		t.Fatal(err)
+	}
+	st = fake.lastStatement()
+	if st.query != "INSERT INTO `users` (`age`, `name`) VALUES(?, ?)" || !reflect.DeepEqual(st.args, []interface{}{int64(3), "a"}) {
+		t.Errorf("unexpected statement %q %v", st.query, st.args)
+	}
+}
+
+func TestUpdateStruct(t *testing.T) {
+	runToSQLCases(t, func() *DbAdapter { return newTestAdapter(MySQL) }, []toSQLCase{
+		{
+			name: "all written fields",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.UpdateStruct(writerUser{ID: 1, Name: "a", Comment: "c"}).WhereCondition(Col("id").Eq(1))
+			},
+			query: "UPDATE `users` SET `name` = ?, `comment` = ? WHERE (`id` = ?)",
+			args:  []interface{}{"a", "c", 1},
+		},
+		{
+			name: "only some fields",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.UpdateStruct(&writerUser{Name: "a"}, "comment").WhereCondition(Col("id").Eq(1))
+			},
+			query: "UPDATE `users` SET `comment` = ? WHERE (`id` = ?)",
+			args:  []interface{}{"", 1},
+		},
+		{
+			name: "unknown field",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.UpdateStruct(writerUser{}, "Missing")
+			},
+			err: "has no field Missing",
+		},
+		{
+			name: "map columns in alphabetical order",
+			build: func(d *DbAdapter) *DbAdapter {
+				return d.UpdateMap(map[string]interface{}{"name": "a", "age": 3})
+			},
+			query: "UPDATE `users` SET `age` = ?, `name` = ?",
+			args:  []interface{}{3, "a"},
+		},
+	})
+}
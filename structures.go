@@ -22,6 +22,7 @@ type ClauseLogic string
 type ClauseOperator string
 type MySqlFunction string
 type JoinType string
+type SetOperator string
 type queryType string
 
 const (
@@ -29,6 +30,7 @@ const (
 	queryTypeSelectRow           = "selectRow"
 	queryTypeUpdate              = "update"
 	queryTypeDelete              = "delete"
+	queryTypeInsert              = "insert"
 )
 
 const (
@@ -71,20 +73,47 @@ const (
 	InnerJoin JoinType = "INNER JOIN"
 	LeftJoin           = "LEFT JOIN"
 	RightJoin          = "RIGHT JOIN"
+	CrossJoin          = "CROSS JOIN"
+	// MySQL only, joins the tables in the order written
+	StraightJoin = "STRAIGHT_JOIN"
+)
+
+const (
+	Union     SetOperator = "UNION"
+	UnionAll              = "UNION ALL"
+	Intersect             = "INTERSECT"
+	Except                = "EXCEPT"
 )
 
 const Distinct string = "DISTINCT"
 const preparationPlaceHolder string = "?"
 
+// ClauseLogic connects the condition to the one following it.
+// If Group is set, the condition is that nested group and
+// Column and ValueAggregatedWithOperator are ignored.
+// Args are the values of the placeholders of the condition,
+// set by the expression builder, see expression.go
 type Clause struct {
 	ClauseLogic                 ClauseLogic
 	Column                      string
 	ValueAggregatedWithOperator string
+	Group                       *Where
+	Args                        []interface{}
+	// Set by MatchColumns, rendered by the dialect
+	fullTextColumns []string
+	// Rendered in parentheses after ValueAggregatedWithOperator
+	subquery Subquery
+	// Rendered quoted after ValueAggregatedWithOperator, see CompareCol
+	comparedColumn string
+	// Rendered as it is instead of the condition, e.g. 1 = 0 for IN ()
+	constant string
 }
 
 type Where struct {
 	WhereLogic ClauseLogic
 	Conditions []Clause
+	// Values of the conditions, set by Where and Having
+	args []interface{}
 }
 
 type Order string
@@ -97,8 +126,17 @@ const (
 type OrderBy struct {
 	Column string
 	Order  Order
+	// Values of the Make* helpers, set by DbAdapter.OrderBy
+	args []interface{}
 }
 
+type LockMode string
+
+const (
+	LockForUpdate LockMode = "FOR UPDATE"
+	LockForShare           = "FOR SHARE"
+)
+
 type limitParams struct {
 	Limit  int
 	Offset int
@@ -109,4 +147,8 @@ type join struct {
 	ForignTable string
 	PrimaryKey  string
 	ForignKey   string
+	Alias       string
+	subquery    Subquery
+	on          *Where
+	using       []string
 }
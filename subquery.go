@@ -0,0 +1,90 @@
+package querybuilder
+
+import "fmt"
+
+// Subquery is a select that can be embedded into another
+// statement, its SQL and values are merged into the outer
+// statement where it is used. Select builders implement it:
+//
+//	orders := d.Table("orders").SelectByColumns([]string{"user_id"}).WhereCondition(querybuilder.Col("total").Gt(100))
+//	d.Select().WhereCondition(querybuilder.Col("id").InSubquery(orders))
+type Subquery interface {
+	buildStatement() (statement, error)
+}
+
+type selectSubquery struct {
+	subquery Subquery
+	alias    string
+}
+
+// Build the statement of a subquery, it keeps "?" placeholders
+// until the outer statement is compiled
+func buildSubquery(subquery Subquery) (statement, error) {
+	if subquery == nil {
+		return statement{}, fmt.Errorf("Subquery is nil.")
+	}
+	if builder, ok := subquery.(*DbAdapter); ok && builder.queryType != queryTypeSelect && builder.queryType != queryTypeSelectRow {
+		return statement{}, fmt.Errorf("Subquery must be a select statement.")
+	}
+	return subquery.buildStatement()
+}
+
+// Select from a derived table instead of the table of the builder
+// Usage: d.FromSubquery(totals, "t").SelectByColumns([]string{"t.user_id"})
+func (d *DbAdapter) FromSubquery(subquery Subquery, alias string) *DbAdapter {
+	d.fromSubquery = &selectSubquery{subquery: subquery, alias: alias}
+	return d
+}
+
+// Add a scalar subquery to the select list, after the columns
+// Usage: d.SelectByColumns([]string{"id"}).SelectSubquery(orderCount, "orders")
+func (d *DbAdapter) SelectSubquery(subquery Subquery, alias string) *DbAdapter {
+	if d.queryType == "" {
+		d.queryType = queryTypeSelect
+	}
+	d.selectSubqueries = append(d.selectSubqueries, selectSubquery{subquery: subquery, alias: alias})
+	return d
+}
+
+// Usage: d.MakeCondition(AND, "id", d.MakeInSubquery(subquery))
+func (d *DbAdapter) MakeInSubquery(subquery Subquery) string {
+	return d.makeSubqueryWithOperator(In, subquery)
+}
+
+func (d *DbAdapter) MakeNotInSubquery(subquery Subquery) string {
+	return d.makeSubqueryWithOperator(NotIn, subquery)
+}
+
+// Compare against a scalar subquery
+// Usage: d.MakeCondition(AND, "price", d.MakeSubqueryWithOperator(GreaterThan, averagePrice))
+func (d *DbAdapter) MakeSubqueryWithOperator(operator ClauseOperator, subquery Subquery) string {
+	return d.makeSubqueryWithOperator(operator, subquery)
+}
+
+func (d *DbAdapter) makeSubqueryWithOperator(operator ClauseOperator, subquery Subquery) string {
+	st, err := buildSubquery(subquery)
+	if err != nil {
+		d.setQueryError(err)
+		return ""
+	}
+	for i := 0; i < len(st.args); i++ {
+		d.setAggregatedValueForClauses(st.args[i])
+	}
+	return fmt.Sprintf("%s (%s)", operator, st.query)
+}
+
+func (c ColumnExpr) InSubquery(subquery Subquery) Clause {
+	return Clause{ClauseLogic: AND, Column: c.column, ValueAggregatedWithOperator: In, subquery: subquery}
+}
+
+func (c ColumnExpr) NotInSubquery(subquery Subquery) Clause {
+	return Clause{ClauseLogic: AND, Column: c.column, ValueAggregatedWithOperator: NotIn, subquery: subquery}
+}
+
+func Exists(subquery Subquery) Clause {
+	return Clause{ClauseLogic: AND, ValueAggregatedWithOperator: "EXISTS", subquery: subquery}
+}
+
+func NotExists(subquery Subquery) Clause {
+	return Clause{ClauseLogic: AND, ValueAggregatedWithOperator: "NOT EXISTS", subquery: subquery}
+}
@@ -0,0 +1,152 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type transaction struct {
+	tx *sql.Tx
+	// Name of the SAVEPOINT for nested transactions,
+	// empty for the outermost one
+	savepoint string
+	// Shared by all nesting levels of the same
+	// transaction to keep savepoint names unique
+	savepointCounter *int
+}
+
+// The subset of *sql.DB and *sql.Tx used to run statements
+type sqlExecutor interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Returns the transaction if the adapter is bound
+// to one, the connection pool otherwise
+func (d *DbAdapter) executor() (sqlExecutor, error) {
+	if d.transaction != nil {
+		return d.transaction.tx, nil
+	}
+	db := d.connection().getDb()
+	if db == nil {
+		return nil, fmt.Errorf("Not connected to the database server.")
+	}
+	return db, nil
+}
+
+func (d *DbAdapter) Begin() (*DbAdapter, error) {
+	return d.BeginContext(context.Background(), nil)
+}
+
+// Starts a transaction and returns an adapter bound to it, the
+// returned adapter has the same table and builder methods as d.
+// Isolation level and read-only mode are set through opts,
+// e.g. &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}
+// If d is already bound to a transaction, a nested transaction
+// is started with a SAVEPOINT, see beginNested
+// @ param ctx context.Context Cancelling it rolls the transaction back
+// @ param opts *sql.TxOptions Can be nil for the driver defaults
+// @ return *DbAdapter, error
+func (d *DbAdapter) BeginContext(ctx context.Context, opts *sql.TxOptions) (*DbAdapter, error) {
+	if d.transaction != nil {
+		return d.beginNested(ctx, opts)
+	}
+	db := d.connection().getDb()
+	if db == nil {
+		return nil, fmt.Errorf("Not connected to the database server.")
+	}
+
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	txAdapter := d.NewQuery()
+	txAdapter.transaction = &transaction{tx: tx, savepointCounter: new(int)}
+	return txAdapter, nil
+}
+
+// Nested transactions share the outer *sql.Tx. Commit releases
+// the savepoint and Rollback only undoes the statements executed
+// since it was set, the outer transaction carries on either way.
+func (d *DbAdapter) beginNested(ctx context.Context, opts *sql.TxOptions) (*DbAdapter, error) {
+	if opts != nil {
+		return nil, fmt.Errorf("Transaction options cannot be set on a nested transaction.")
+	}
+
+	*d.transaction.savepointCounter++
+	savepoint := fmt.Sprintf("sp_%d", *d.transaction.savepointCounter)
+
+	if _, err := d.transaction.tx.ExecContext(ctx, fmt.Sprintf("SAVEPOINT %s", savepoint)); err != nil {
+		return nil, err
+	}
+
+	txAdapter := d.NewQuery()
+	txAdapter.transaction = &transaction{tx: d.transaction.tx, savepoint: savepoint, savepointCounter: d.transaction.savepointCounter}
+	return txAdapter, nil
+}
+
+func (d *DbAdapter) Commit() error {
+	if d.transaction == nil {
+		return fmt.Errorf("Commit failed. No transaction started.")
+	}
+	if d.transaction.savepoint != "" {
+		_, err := d.transaction.tx.Exec(fmt.Sprintf("RELEASE SAVEPOINT %s", d.transaction.savepoint))
+		return err
+	}
+	return d.transaction.tx.Commit()
+}
+
+func (d *DbAdapter) Rollback() error {
+	if d.transaction == nil {
+		return fmt.Errorf("Rollback failed. No transaction started.")
+	}
+	if d.transaction.savepoint != "" {
+		_, err := d.transaction.tx.Exec(fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", d.transaction.savepoint))
+		return err
+	}
+	return d.transaction.tx.Rollback()
+}
+
+// Returns true if the adapter is bound to a transaction
+func (d *DbAdapter) InTransaction() bool {
+	return d.transaction != nil
+}
+
+// Returns true if the adapter is bound to a nested transaction
+func (d *DbAdapter) InNestedTransaction() bool {
+	return d.transaction != nil && d.transaction.savepoint != ""
+}
+
+func (d *DbAdapter) WithTransaction(fn func(tx *DbAdapter) error) error {
+	return d.WithTransactionContext(context.Background(), nil, fn)
+}
+
+// Runs fn inside a transaction, commits if fn returns nil and
+// rolls back if it returns an error or panics. A panic is
+// re-raised after the rollback. Called on a transaction bound
+// adapter fn runs in a nested transaction, so only its own
+// statements are rolled back.
+func (d *DbAdapter) WithTransactionContext(ctx context.Context, opts *sql.TxOptions, fn func(tx *DbAdapter) error) error {
+	txAdapter, err := d.BeginContext(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			txAdapter.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err = fn(txAdapter); err != nil {
+		if rollbackErr := txAdapter.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+
+	return txAdapter.Commit()
+}
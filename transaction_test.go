@@ -0,0 +1,137 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWithTransactionCommits(t *testing.T) {
+	d, fake := newFakeAdapter(t)
+	err := d.WithTransaction(func(tx *DbAdapter) error {
+		if !tx.InTransaction() || tx.InNestedTransaction() {
+			t.Errorf("expected an outer transaction")
+		}
+		_, err := tx.Insert([]string{"name"}, []interface{}{"a"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"BEGIN", "INSERT INTO `users` (`name`) VALUES(?)", "COMMIT"}
+	if got := fake.queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithTransactionRollsBackOnError(t *testing.T) {
+	d, fake := newFakeAdapter(t)
+	failed := errors.New("failed")
+	err := d.WithTransaction(func(tx *DbAdapter) error {
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+
+	want := []string{"BEGIN", "ROLLBACK"}
+	if got := fake.queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithTransactionRollsBackOnPanic(t *testing.T) {
+	d, fake := newFakeAdapter(t)
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("expected the panic to be re-raised, got %v", p)
+		}
+		want := []string{"BEGIN", "ROLLBACK"}
+		if got := fake.queries(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}()
+	d.WithTransaction(func(tx *DbAdapter) error {
+		panic("boom")
+	})
+}
+
+func TestCommitWithoutTransaction(t *testing.T) {
+	d, _ := newFakeAdapter(t)
+	if err := d.Commit(); err == nil {
+		t.Errorf("expected Commit to fail without a transaction")
+	}
+	if err := d.Rollback(); err == nil {
+		t.Errorf("expected Rollback to fail without a transaction")
+	}
+}
+
+func TestBuildersOfATransactionShareIt(t *testing.T) {
+	d, fake := newFakeAdapter(t)
+	tx, err := d.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Table("orders").Delete().WhereCondition(Col("id").Eq(1)).ExecDelete(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"BEGIN", "DELETE FROM `orders` WHERE (`id` = ?)", "ROLLBACK"}
+	if got := fake.queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNestedTransactionsUseSavepoints(t *testing.T) {
+	d, fake := newFakeAdapter(t)
+	failed := errors.New("failed")
+	err := d.WithTransaction(func(tx *DbAdapter) error {
+		err := tx.WithTransaction(func(nested *DbAdapter) error {
+			if !nested.InNestedTransaction() {
+				t.Errorf("expected a nested transaction")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := tx.WithTransaction(func(nested *DbAdapter) error { return failed }); !errors.Is(err, failed) {
+			t.Errorf("expected the error of the nested fn, got %v", err)
+		}
+		// The outer transaction carries on after the nested rollback
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"BEGIN",
+		"SAVEPOINT sp_1",
+		"RELEASE SAVEPOINT sp_1",
+		"SAVEPOINT sp_2",
+		"ROLLBACK TO SAVEPOINT sp_2",
+		"COMMIT",
+	}
+	if got := fake.queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNestedTransactionRejectsOptions(t *testing.T) {
+	d, _ := newFakeAdapter(t)
+	tx, err := d.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.BeginContext(context.Background(), &sql.TxOptions{ReadOnly: true}); err == nil {
+		t.Errorf("expected options of a nested transaction to fail")
+	}
+}
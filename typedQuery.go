@@ -0,0 +1,129 @@
+package querybuilder
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TypedQuery is a select builder returning rows as T, the selected
+// columns are derived from the fields of T, see structScanner.go
+//
+//	users, err := querybuilder.Query[User](d).Where(...).OrderBy(...).All(ctx)
+//
+// Conditions using Make* helpers have to be made on Builder(), since
+// those helpers collect the values on the builder they are called on.
+// Conditions of the expression builder need no builder:
+//
+//	querybuilder.Query[User](d).WhereCondition(querybuilder.Col("age").Gte(18)).All(ctx)
+type TypedQuery[T any] struct {
+	builder *DbAdapter
+}
+
+// Returns a typed query on the table of d, T must be a struct
+func Query[T any](d *DbAdapter) *TypedQuery[T] {
+	builder := d.NewQuery()
+
+	structType := reflect.TypeOf((*T)(nil)).Elem()
+	if structType.Kind() != reflect.Struct {
+		builder.setQueryError(fmt.Errorf("Query needs a struct type, got %s.", structType))
+	} else {
+		builder.SelectByColumns(builder.structColumns(structType))
+	}
+
+	return &TypedQuery[T]{builder: builder}
+}
+
+// The underlying builder, for the Make* helpers
+func (q *TypedQuery[T]) Builder() *DbAdapter {
+	return q.builder
+}
+
+func (q *TypedQuery[T]) Where(whereGroup Where) *TypedQuery[T] {
+	q.builder.Where(whereGroup)
+	return q
+}
+
+func (q *TypedQuery[T]) WhereCondition(condition Clause) *TypedQuery[T] {
+	q.builder.WhereCondition(condition)
+	return q
+}
+
+func (q *TypedQuery[T]) Join(joinType JoinType, forignTable, primaryKey, forignKey string) *TypedQuery[T] {
+	q.builder.Join(joinType, forignTable, primaryKey, forignKey)
+	return q
+}
+
+func (q *TypedQuery[T]) OrderBy(orderBy OrderBy) *TypedQuery[T] {
+	q.builder.OrderBy(orderBy)
+	return q
+}
+
+func (q *TypedQuery[T]) GroupBy(groupBy []string) *TypedQuery[T] {
+	q.builder.GroupBy(groupBy)
+	return q
+}
+
+func (q *TypedQuery[T]) Having(havingGroup Where) *TypedQuery[T] {
+	q.builder.Having(havingGroup)
+	return q
+}
+
+func (q *TypedQuery[T]) Limit(limit int, offset ...int) *TypedQuery[T] {
+	q.builder.Limit(limit, offset...)
+	return q
+}
+
+func (q *TypedQuery[T]) ToSQL() (string, []interface{}, error) {
+	return q.builder.ToSQL()
+}
+
+// TypedQuery can be used as a Subquery
+func (q *TypedQuery[T]) buildStatement() (statement, error) {
+	return q.builder.buildStatement()
+}
+
+func (q *TypedQuery[T]) All(ctx context.Context) ([]T, error) {
+	var rows []T
+	if err := q.builder.ExecSelectIntoContext(ctx, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Returns the first row, ErrNoRows if nothing matched
+func (q *TypedQuery[T]) First(ctx context.Context) (T, error) {
+	var row T
+	q.builder.Limit(1)
+	rows, err := q.All(ctx)
+	if err != nil {
+		return row, err
+	}
+	if len(rows) == 0 {
+		return row, ErrNoRows
+	}
+	return rows[0], nil
+}
+
+// The select list for the fields of structType. Fields of nested
+// structs are aliased with their full name so they can be mapped
+// back, e.g. author.name AS `author.name`
+func (d *DbAdapter) structColumns(structType reflect.Type) []string {
+	fields := getStructMapping(structType).fields
+	columns := []string{}
+	for i := 0; i < len(fields); i++ {
+		column := fields[i].column
+		if strings.Contains(column, ".") {
+			column = d.MakeAsField(column, quoteAlias(column, d.GetDialect()))
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// Quote an alias as a single identifier, dots included
+func quoteAlias(alias string, dialect Dialect) string {
+	quote := dialect.QuoteIdentifier("")[:1]
+	return quote + strings.ReplaceAll(alias, quote, quote+quote) + quote
+}
@@ -0,0 +1,87 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type typedAuthor struct {
+	ID   int64 `db:"id"`
+	Name string
+}
+
+type typedPost struct {
+	ID     int64 `db:"id"`
+	Title  string
+	Author typedAuthor `db:"author"`
+	Draft  bool        `db:"-"`
+}
+
+func TestQueryColumns(t *testing.T) {
+	runToSQLCases(t, func() *DbAdapter { return newTestAdapter(MySQL) }, []toSQLCase{
+		{
+			name: "columns of the fields, nested ones aliased",
+			build: func(d *DbAdapter) *DbAdapter {
+				return Query[typedPost](d).WhereCondition(Col("id").Gt(1)).Limit(5).Builder()
+			},
+			query: "SELECT `id`, `title`, `author`.`id` AS `author.id`, `author`.`name` AS `author.name` FROM `users` WHERE (`id` > ?) LIMIT 5 OFFSET 0",
+			args:  []interface{}{1},
+		},
+		{
+			name: "T must be a struct",
+			build: func(d *DbAdapter) *DbAdapter {
+				return Query[int](d).Builder()
+			},
+			err: "needs a struct type",
+		},
+	})
+
+	q := Query[typedPost](newTestAdapter(PostgreSQL))
+	query, _, err := q.ToSQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `SELECT "id", "title", "author"."id" AS "author.id", "author"."name" AS "author.name" FROM "users"`
+	if query != want {
+		t.Errorf("query\n got: %s\nwant: %s", query, want)
+	}
+}
+
+func TestQueryAllAndFirst(t *testing.T) {
+	d, fake := newFakeAdapter(t)
+	fake.setRows([]string{"id", "title", "author.name"},
+		[]driver.Value{int64(1), "Hello", "Ada"},
+		[]driver.Value{int64(2), "World", "Bob"},
+	)
+
+	posts, err := Query[typedPost](d).All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []typedPost{
+		{ID: 1, Title: "Hello", Author: typedAuthor{Name: "Ada"}},
+		{ID: 2, Title: "World", Author: typedAuthor{Name: "Bob"}},
+	}
+	if !reflect.DeepEqual(posts, want) {
+		t.Errorf("got %+v, want %+v", posts, want)
+	}
+
+	post, err := Query[typedPost](d).First(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.ID != 1 {
+		t.Errorf("expected the first row, got %+v", post)
+	}
+	if query := fake.lastStatement().query; query != "SELECT `id`, `title`, `author`.`id` AS `author.id`, `author`.`name` AS `author.name` FROM `users` LIMIT 1 OFFSET 0" {
+		t.Errorf("unexpected query of First: %s", query)
+	}
+
+	fake.setRows([]string{"id"})
+	if _, err := Query[typedPost](d).First(context.Background()); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package querybuilder
+
+import "fmt"
+
+type InsertMode string
+
+const (
+	ModeInsert       InsertMode = "INSERT INTO"
+	ModeInsertIgnore            = "INSERT IGNORE INTO"
+	ModeReplace                 = "REPLACE INTO"
+)
+
+type UpsertAction string
+
+const (
+	// Take the value of the row that was about to be inserted
+	UseNewValue UpsertAction = "new"
+	// Leave the column of the existing row as it is
+	KeepExisting = "keep"
+	// Add Value to the existing column, 1 if Value is nil
+	Increment = "increment"
+	// Set the column to Value
+	SetValue = "value"
+)
+
+// What to do with a column of an existing row when an
+// insert runs into a duplicate key
+type UpsertColumn struct {
+	Column string
+	Action UpsertAction
+	Value  interface{}
+}
+
+// The next Insert, InsertBatch... skips rows that would
+// duplicate a key, ON CONFLICT DO NOTHING on PostgreSQL
+func (d *DbAdapter) InsertIgnore() *DbAdapter {
+	d.insertMode = ModeInsertIgnore
+	return d
+}
+
+// The next Insert, InsertBatch... replaces rows with a duplicate
+// key, not supported by PostgreSQL, use OnDuplicateKeyUpdate there
+func (d *DbAdapter) ReplaceInto() *DbAdapter {
+	d.insertMode = ModeReplace
+	return d
+}
+
+// The next Insert, InsertBatch... updates the existing row on a
+// duplicate key. PostgreSQL and SQLite render it as ON CONFLICT
+// and need the columns of the unique key in conflictColumns,
+// MySQL ignores them.
+// Usage:
+//
+//	d.OnDuplicateKeyUpdate([]string{"email"},
+//		UpsertColumn{Column: "name", Action: UseNewValue},
+//		UpsertColumn{Column: "logins", Action: Increment},
+//	).Insert(columns, values)
+func (d *DbAdapter) OnDuplicateKeyUpdate(conflictColumns []string, updates ...UpsertColumn) *DbAdapter {
+	if len(updates) == 0 {
+		d.setQueryError(fmt.Errorf("OnDuplicateKeyUpdate needs at least one column to update."))
+	}
+	d.upsertConflictColumns = conflictColumns
+	d.upsertColumns = updates
+	return d
+}
+
+// Statement verb and the clause that follows the VALUES
+// lists, with its arguments
+func (d *DbAdapter) prepareInsertModifiers(st *statement, table string) (string, string, []interface{}, error) {
+	mode := d.insertMode
+	if mode == "" {
+		mode = ModeInsert
+	}
+
+	if len(d.upsertColumns) != 0 && mode != ModeInsert {
+		return "", "", nil, fmt.Errorf("OnDuplicateKeyUpdate cannot be combined with %s.", mode)
+	}
+
+	dialect := d.GetDialect()
+	verb, clause, err := dialect.InsertVerb(mode)
+	if err != nil || len(d.upsertColumns) == 0 {
+		return verb, clause, nil, err
+	}
+
+	// The dialect gets every identifier quoted
+	conflictColumns, err := d.renderColumns(st, d.upsertConflictColumns)
+	if err != nil {
+		return "", "", nil, err
+	}
+	upsertColumns := make([]UpsertColumn, len(d.upsertColumns))
+	for i := 0; i < len(d.upsertColumns); i++ {
+		upsertColumns[i] = d.upsertColumns[i]
+		if upsertColumns[i].Column, err = d.renderColumn(st, d.upsertColumns[i].Column); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	clause, args, err := dialect.Upsert(table, conflictColumns, upsertColumns)
+	return verb, clause, args, err
+}